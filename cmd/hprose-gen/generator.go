@@ -0,0 +1,611 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * cmd/hprose-gen/generator.go                            *
+ *                                                        *
+ * hprose code generator for Go.                          *
+ *                                                        *
+ * LastModified: Oct 17, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const rpcPath = "github.com/hprose/hprose-golang/rpc"
+const ioPath = "github.com/hprose/hprose-golang/io"
+
+type param struct {
+	typ      string
+	variadic bool
+}
+
+type method struct {
+	name     string
+	remote   string
+	ctxType  string
+	params   []param
+	results  []string
+	hasError bool
+	oneway   bool
+	settings []string
+}
+
+type iface struct {
+	name    string
+	methods []*method
+}
+
+var readers = map[string]string{
+	"int":        "int(reader.ReadInt())",
+	"int8":       "int8(reader.ReadInt())",
+	"int16":      "int16(reader.ReadInt())",
+	"int32":      "int32(reader.ReadInt())",
+	"int64":      "reader.ReadInt()",
+	"uint":       "uint(reader.ReadUint())",
+	"uint8":      "uint8(reader.ReadUint())",
+	"byte":       "byte(reader.ReadUint())",
+	"uint16":     "uint16(reader.ReadUint())",
+	"uint32":     "uint32(reader.ReadUint())",
+	"uint64":     "reader.ReadUint()",
+	"float32":    "reader.ReadFloat32()",
+	"float64":    "reader.ReadFloat64()",
+	"complex64":  "reader.ReadComplex64()",
+	"complex128": "reader.ReadComplex128()",
+	"bool":       "reader.ReadBool()",
+	"string":     "reader.ReadString()",
+}
+
+var boolSettings = map[string]string{
+	"byref":      "ByRef",
+	"simple":     "Simple",
+	"idempotent": "Idempotent",
+	"failswitch": "Failswitch",
+	"oneway":     "Oneway",
+	"jsoncompat": "JSONCompatible",
+	"coalesce":   "Coalesce",
+}
+
+var durationSettings = map[string]string{
+	"timeout": "Timeout",
+	"hedge":   "Hedge",
+	"cache":   "Cache",
+}
+
+var resultModes = map[string]string{
+	"normal":        "rpc.Normal",
+	"serialized":    "rpc.Serialized",
+	"raw":           "rpc.Raw",
+	"rawwithendtag": "rpc.RawWithEndTag",
+}
+
+type generator struct {
+	buf      bytes.Buffer
+	imports  map[string]string
+	used     map[string]string
+	timePkg  string
+	ctxPkg   string
+	packages map[string]bool
+}
+
+func generate(
+	filename string, src []byte, names []string,
+	genClient, genService bool) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{
+		imports: make(map[string]string),
+		used:    make(map[string]string),
+	}
+	for _, spec := range file.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		name := path.Base(p)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		g.imports[name] = p
+		if p == "time" {
+			g.timePkg = name
+		}
+	}
+	ifaces, err := g.parseInterfaces(file, names)
+	if err != nil {
+		return nil, err
+	}
+	for _, it := range ifaces {
+		if genClient {
+			g.writeClient(it)
+		}
+		if genService {
+			g.writeService(it)
+		}
+	}
+	imports := map[string]string{"reflect": "reflect", "rpc": rpcPath}
+	if genService {
+		imports["hio"] = ioPath
+	}
+	for name, p := range g.used {
+		imports[name] = p
+	}
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by hprose-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\nimport (\n", file.Name.Name)
+	var std, others []string
+	for name, p := range imports {
+		spec := fmt.Sprintf("%q", p)
+		if path.Base(p) != name {
+			spec = name + " " + spec
+		}
+		if strings.Contains(strings.SplitN(p, "/", 2)[0], ".") {
+			others = append(others, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(others)
+	for _, spec := range std {
+		fmt.Fprintf(&out, "\t%s\n", spec)
+	}
+	if len(std) > 0 && len(others) > 0 {
+		fmt.Fprintf(&out, "\n")
+	}
+	for _, spec := range others {
+		fmt.Fprintf(&out, "\t%s\n", spec)
+	}
+	fmt.Fprintf(&out, ")\n\n")
+	out.Write(g.buf.Bytes())
+	code, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("can't format the generated code: %v", err)
+	}
+	return code, nil
+}
+
+func (g *generator) parseInterfaces(
+	file *ast.File, names []string) (ifaces []*iface, err error) {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[strings.TrimSpace(name)] = true
+	}
+	for _, decl := range file.Decls {
+		decl, ok := decl.(*ast.GenDecl)
+		if !ok || decl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range decl.Specs {
+			spec := spec.(*ast.TypeSpec)
+			it, ok := spec.Type.(*ast.InterfaceType)
+			if !ok || len(wanted) > 0 && !wanted[spec.Name.Name] {
+				continue
+			}
+			delete(wanted, spec.Name.Name)
+			i, err := g.parseInterface(spec.Name.Name, it)
+			if err != nil {
+				return nil, err
+			}
+			ifaces = append(ifaces, i)
+		}
+	}
+	for name := range wanted {
+		return nil, fmt.Errorf("interface %s is not found", name)
+	}
+	if len(ifaces) == 0 {
+		return nil, errors.New("no interface is found")
+	}
+	return ifaces, nil
+}
+
+func (g *generator) parseInterface(name string, it *ast.InterfaceType) (*iface, error) {
+	i := &iface{name: name}
+	for _, field := range it.Methods.List {
+		if len(field.Names) == 0 {
+			return nil, fmt.Errorf("%s: embedded interface is not supported", name)
+		}
+		m, err := g.parseMethod(field.Names[0].Name, field.Type.(*ast.FuncType))
+		if err == nil {
+			err = m.parseDirectives(field.Doc)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", name, field.Names[0].Name, err)
+		}
+		i.methods = append(i.methods, m)
+	}
+	return i, nil
+}
+
+func fieldCount(field *ast.Field) int {
+	if len(field.Names) == 0 {
+		return 1
+	}
+	return len(field.Names)
+}
+
+func (g *generator) parseMethod(name string, ft *ast.FuncType) (*method, error) {
+	m := &method{name: name, remote: name}
+	for i, field := range ft.Params.List {
+		typ, err := g.typeString(field.Type)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 && typ == g.ctxPkg+".Context" && g.imports[g.ctxPkg] == "context" {
+			m.ctxType = typ
+			continue
+		}
+		p := param{typ: typ}
+		if ellipsis, ok := field.Type.(*ast.Ellipsis); ok {
+			p.typ, _ = g.typeString(ellipsis.Elt)
+			p.variadic = true
+		}
+		for n := fieldCount(field); n > 0; n-- {
+			m.params = append(m.params, p)
+		}
+	}
+	if ft.Results != nil {
+		for _, field := range ft.Results.List {
+			typ, err := g.typeString(field.Type)
+			if err != nil {
+				return nil, err
+			}
+			for n := fieldCount(field); n > 0; n-- {
+				m.results = append(m.results, typ)
+			}
+		}
+	}
+	if n := len(m.results); n > 0 && m.results[n-1] == "error" {
+		m.results = m.results[:n-1]
+		m.hasError = true
+	}
+	return m, nil
+}
+
+// typeString returns the source of the type expression and records the
+// imported packages which it uses.
+func (g *generator) typeString(expr ast.Expr) (string, error) {
+	var err error
+	ast.Inspect(expr, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				p, ok := g.imports[id.Name]
+				if !ok {
+					err = fmt.Errorf("package %s is not imported", id.Name)
+				} else if p == "context" && sel.Sel.Name == "Context" {
+					g.ctxPkg = id.Name
+				}
+			}
+			return false
+		}
+		return true
+	})
+	return types.ExprString(expr), err
+}
+
+// use records the imported package which is used by the generated code
+func (g *generator) use(typ string) {
+	for name, p := range g.imports {
+		if strings.Contains(typ, name+".") {
+			g.used[name] = p
+		}
+	}
+}
+
+func (m *method) parseDirectives(doc *ast.CommentGroup) error {
+	if doc == nil {
+		return nil
+	}
+	for _, comment := range doc.List {
+		text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+		if !strings.HasPrefix(text, "hprose:") {
+			continue
+		}
+		for _, directive := range strings.Fields(text[len("hprose:"):]) {
+			key, value := directive, ""
+			if i := strings.Index(directive, "="); i >= 0 {
+				key, value = directive[:i], directive[i+1:]
+			}
+			if err := m.setDirective(key, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *method) setDirective(key, value string) error {
+	if field, ok := boolSettings[key]; ok {
+		b := true
+		if value != "" {
+			var err error
+			if b, err = strconv.ParseBool(value); err != nil {
+				return fmt.Errorf("invalid %s: %s", key, value)
+			}
+		}
+		if b {
+			m.settings = append(m.settings, field+": true,")
+			m.oneway = m.oneway || key == "oneway"
+		}
+		return nil
+	}
+	switch key {
+	case "name":
+		if value == "" {
+			return errors.New("name can't be empty")
+		}
+		m.remote = value
+	case "retry":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid retry: %s", value)
+		}
+		m.settings = append(m.settings, fmt.Sprintf("Retry: %d,", n))
+	case "timeout", "hedge", "cache":
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %s", key, value)
+		}
+		m.settings = append(m.settings, fmt.Sprintf("%s: %d, // %s",
+			durationSettings[key], int64(d), value))
+	case "result":
+		mode, ok := resultModes[strings.ToLower(value)]
+		if !ok {
+			return fmt.Errorf("invalid result: %s", value)
+		}
+		m.settings = append(m.settings, "Mode: "+mode+",")
+	default:
+		return fmt.Errorf("unknown directive: %s", key)
+	}
+	return nil
+}
+
+func lowerFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[n:]
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (m *method) fixedParams() int {
+	n := len(m.params)
+	if n > 0 && m.params[n-1].variadic {
+		n--
+	}
+	return n
+}
+
+func (m *method) signature() string {
+	var params, results []string
+	if m.ctxType != "" {
+		params = append(params, "ctx "+m.ctxType)
+	}
+	for i, p := range m.params {
+		if p.variadic {
+			params = append(params, fmt.Sprintf("a%d ...%s", i, p.typ))
+		} else {
+			params = append(params, fmt.Sprintf("a%d %s", i, p.typ))
+		}
+	}
+	for i, r := range m.results {
+		results = append(results, fmt.Sprintf("r%d %s", i, r))
+	}
+	if m.hasError {
+		results = append(results, "err error")
+	}
+	s := m.name + "(" + strings.Join(params, ", ") + ")"
+	if len(results) > 0 {
+		s += " (" + strings.Join(results, ", ") + ")"
+	}
+	return s
+}
+
+func (g *generator) writeClient(it *iface) {
+	client := it.name + "Client"
+	g.printf(`// %[1]s is the hprose client of %[2]s
+type %[1]s struct {
+	client rpc.Client
+	prefix string
+}
+
+// New%[1]s returns the hprose client of %[2]s,
+// the namespace is the prefix of the remote method names.
+func New%[1]s(client rpc.Client, namespace ...string) *%[1]s {
+	c := &%[1]s{client: client}
+	if len(namespace) == 1 && namespace[0] != "" {
+		c.prefix = namespace[0] + "_"
+	}
+	return c
+}
+
+var _ %[2]s = (*%[1]s)(nil)
+
+`, client, it.name)
+	for _, m := range it.methods {
+		g.writeClientMethod(client, lowerFirst(it.name)+m.name+"Settings", m)
+	}
+}
+
+func (g *generator) writeClientMethod(client, settings string, m *method) {
+	g.use(m.ctxType)
+	g.printf("var %s = &rpc.InvokeSettings{\n", settings)
+	for _, s := range m.settings {
+		g.printf("\t%s\n", s)
+	}
+	if len(m.results) > 0 {
+		g.printf("\tResultTypes: []reflect.Type{\n")
+		for _, r := range m.results {
+			g.use(r)
+			g.printf("\t\treflect.TypeOf((*%s)(nil)).Elem(),\n", r)
+		}
+		g.printf("\t},\n")
+	}
+	g.printf("}\n\n")
+	g.printf("// %s invokes the remote method %s\n", m.name, m.remote)
+	g.printf("func (c *%s) %s {\n", client, m.signature())
+	args := "nil"
+	if len(m.params) > 0 {
+		args = "args"
+		g.printf("\targs := []reflect.Value{\n")
+		for i, p := range m.params {
+			g.use(p.typ)
+			if !p.variadic {
+				g.printf("\t\treflect.ValueOf(&a%d).Elem(),\n", i)
+			}
+		}
+		g.printf("\t}\n")
+		if n := m.fixedParams(); n < len(m.params) {
+			g.printf("\tfor i := range a%[1]d {\n", n)
+			g.printf("\t\targs = append(args, reflect.ValueOf(&a%d[i]).Elem())\n", n)
+			g.printf("\t}\n")
+		}
+	}
+	results := "_"
+	if len(m.results) > 0 && !m.oneway {
+		results = "results"
+	}
+	if m.ctxType != "" {
+		g.printf("\t%s, e := c.client.InvokeContext(ctx, c.prefix+%q, %s, %s)\n",
+			results, m.remote, args, settings)
+	} else {
+		g.printf("\t%s, e := c.client.Invoke(c.prefix+%q, %s, %s)\n",
+			results, m.remote, args, settings)
+	}
+	g.printf("\tif e != nil {\n")
+	if m.hasError {
+		g.printf("\t\terr = e\n\t\treturn\n")
+	} else {
+		g.printf("\t\tpanic(e)\n")
+	}
+	g.printf("\t}\n")
+	if results != "_" {
+		for i, r := range m.results {
+			g.printf("\tr%d, _ = results[%d].Interface().(%s)\n", i, i, r)
+		}
+	}
+	g.printf("\treturn\n}\n\n")
+}
+
+func (g *generator) writeService(it *iface) {
+	for _, m := range it.methods {
+		g.writeInvoker(lowerFirst(it.name)+m.name+"Invoker", it.name, m)
+	}
+	g.printf(`// Register%[1]sService publishes the methods of impl to service,
+// the methods are invoked without reflection.
+func Register%[1]sService(service rpc.Service, impl %[1]s, options rpc.Options) {
+`, it.name)
+	for _, m := range it.methods {
+		g.printf("\tservice.AddInvoker(%q, %s{impl}, options)\n",
+			m.remote, lowerFirst(it.name)+m.name+"Invoker")
+	}
+	g.printf("}\n\n")
+}
+
+func (g *generator) readExpr(typ, v string) string {
+	if g.timePkg != "" && typ == g.timePkg+".Time" {
+		return v + " = reader.ReadTime()"
+	}
+	if reader, ok := readers[typ]; ok {
+		return v + " = " + reader
+	}
+	return "reader.Unserialize(&" + v + ")"
+}
+
+func (g *generator) writeInvoker(invoker, name string, m *method) {
+	n := m.fixedParams()
+	g.use(m.ctxType)
+	for _, p := range m.params {
+		g.use(p.typ)
+	}
+	for _, r := range m.results {
+		g.use(r)
+	}
+	g.printf("type %s struct {\n\timpl %s\n}\n\n", invoker, name)
+	g.printf("func (invoker %s) ReadArguments(reader *hio.Reader, count int) []reflect.Value {\n", invoker)
+	for i := 0; i < n; i++ {
+		g.printf("\tvar a%d %s\n", i, m.params[i].typ)
+	}
+	g.printf("\targs := make([]reflect.Value, %d)\n", n)
+	g.printf("\tif count > %d {\n\t\targs = make([]reflect.Value, count)\n\t}\n", n)
+	g.printf("\treader.ReadSliceFunc(count, func(i int) {\n\t\tswitch i {\n")
+	for i := 0; i < n; i++ {
+		g.printf("\t\tcase %d:\n\t\t\t%s\n", i, g.readExpr(m.params[i].typ, fmt.Sprintf("a%d", i)))
+	}
+	g.printf("\t\tdefault:\n")
+	if n < len(m.params) {
+		typ := m.params[n].typ
+		g.printf("\t\t\tvar v %s\n\t\t\t%s\n", typ, g.readExpr(typ, "v"))
+	} else {
+		g.printf("\t\t\tvar v interface{}\n\t\t\treader.Unserialize(&v)\n")
+	}
+	g.printf("\t\t\targs[i] = reflect.ValueOf(&v).Elem()\n\t\t}\n\t})\n")
+	for i := 0; i < n; i++ {
+		g.printf("\targs[%[1]d] = reflect.ValueOf(&a%[1]d).Elem()\n", i)
+	}
+	g.printf("\treturn args\n}\n\n")
+
+	g.printf("func (invoker %s) Invoke(args []reflect.Value) ([]reflect.Value, error) {\n", invoker)
+	var params []string
+	if m.ctxType != "" {
+		params = append(params, strings.TrimSuffix(m.ctxType, "Context")+"Background()")
+	}
+	for i := 0; i < n; i++ {
+		g.printf("\ta%[1]d, _ := args[%[1]d].Interface().(%[2]s)\n", i, m.params[i].typ)
+		params = append(params, fmt.Sprintf("a%d", i))
+	}
+	if n < len(m.params) {
+		g.printf("\ta%[1]d := make([]%[2]s, len(args)-%[1]d)\n", n, m.params[n].typ)
+		g.printf("\tfor i := range a%d {\n", n)
+		g.printf("\t\ta%[1]d[i], _ = args[%[1]d+i].Interface().(%[2]s)\n", n, m.params[n].typ)
+		g.printf("\t}\n")
+		params = append(params, fmt.Sprintf("a%d...", n))
+	}
+	call := fmt.Sprintf("invoker.impl.%s(%s)", m.name, strings.Join(params, ", "))
+	var results, values []string
+	for i := range m.results {
+		results = append(results, fmt.Sprintf("r%d", i))
+		values = append(values, fmt.Sprintf("reflect.ValueOf(&r%d).Elem()", i))
+	}
+	err := "nil"
+	if m.hasError {
+		results = append(results, "err")
+		err = "err"
+	}
+	if len(results) > 0 {
+		g.printf("\t%s := %s\n", strings.Join(results, ", "), call)
+	} else {
+		g.printf("\t%s\n", call)
+	}
+	if len(values) > 0 {
+		g.printf("\treturn []reflect.Value{%s}, %s\n}\n\n", strings.Join(values, ", "), err)
+	} else {
+		g.printf("\treturn nil, %s\n}\n\n", err)
+	}
+}
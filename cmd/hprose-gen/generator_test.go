@@ -0,0 +1,84 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * cmd/hprose-gen/generator_test.go                       *
+ *                                                        *
+ * hprose-gen generator test for Go.                      *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// TestGolden checks the generated code of the example, which is compiled and
+// tested over tcp in the example package.
+func TestGolden(t *testing.T) {
+	input := filepath.Join("internal", "example", "arith.go")
+	golden := filepath.Join("internal", "example", "arith_hprose.go")
+	src, err := ioutil.ReadFile(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := generate(input, src, []string{"Arith"}, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		if err = ioutil.WriteFile(golden, code, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(code, want) {
+		t.Errorf("the generated code differs from %s, "+
+			"run go test -update if the change is expected:\n%s",
+			golden, code)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		err string
+	}{
+		{"package p\ntype I interface {\n\t// hprose:retry=x\n\tF()\n}\n",
+			"invalid retry: x"},
+		{"package p\ntype I interface {\n\t// hprose:timeout=1\n\tF()\n}\n",
+			"invalid timeout: 1"},
+		{"package p\ntype I interface {\n\t// hprose:result=json\n\tF()\n}\n",
+			"invalid result: json"},
+		{"package p\ntype I interface {\n\t// hprose:unknown\n\tF()\n}\n",
+			"unknown directive: unknown"},
+		{"package p\ntype I interface {\n\tF(t time.Time)\n}\n",
+			"package time is not imported"},
+	}
+	for _, test := range tests {
+		_, err := generate("p.go", []byte(test.src), nil, true, true)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%q: err is %v, want %s", test.src, err, test.err)
+		}
+	}
+}
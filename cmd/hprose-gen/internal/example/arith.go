@@ -0,0 +1,51 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * cmd/hprose-gen/internal/example/arith.go               *
+ *                                                        *
+ * hprose-gen example for Go.                             *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+// Package example is the input of the hprose-gen golden test, arith_hprose.go
+// is generated from arith.go and tested over tcp.
+package example
+
+//go:generate go run github.com/hprose/hprose-golang/cmd/hprose-gen -type Arith arith.go
+
+import (
+	"context"
+	"time"
+)
+
+// Args is the arguments of Divide
+type Args struct {
+	A, B int
+}
+
+// Quotient is the result of Divide
+type Quotient struct {
+	Quo, Rem int
+}
+
+// Arith is the example service
+type Arith interface {
+	Multiply(a, b int) int
+	// hprose:idempotent retry=2 timeout=5s
+	Divide(args *Args) (*Quotient, error)
+	Sum(base float64, nums ...int) float64
+	Now(ctx context.Context, name string) (time.Time, error)
+	// hprose:name=pair
+	Pair(s []string, m map[string]int) (string, int)
+	Nothing()
+}
@@ -0,0 +1,348 @@
+// Code generated by hprose-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	hio "github.com/hprose/hprose-golang/io"
+	"github.com/hprose/hprose-golang/rpc"
+)
+
+// ArithClient is the hprose client of Arith
+type ArithClient struct {
+	client rpc.Client
+	prefix string
+}
+
+// NewArithClient returns the hprose client of Arith,
+// the namespace is the prefix of the remote method names.
+func NewArithClient(client rpc.Client, namespace ...string) *ArithClient {
+	c := &ArithClient{client: client}
+	if len(namespace) == 1 && namespace[0] != "" {
+		c.prefix = namespace[0] + "_"
+	}
+	return c
+}
+
+var _ Arith = (*ArithClient)(nil)
+
+var arithMultiplySettings = &rpc.InvokeSettings{
+	ResultTypes: []reflect.Type{
+		reflect.TypeOf((*int)(nil)).Elem(),
+	},
+}
+
+// Multiply invokes the remote method Multiply
+func (c *ArithClient) Multiply(a0 int, a1 int) (r0 int) {
+	args := []reflect.Value{
+		reflect.ValueOf(&a0).Elem(),
+		reflect.ValueOf(&a1).Elem(),
+	}
+	results, e := c.client.Invoke(c.prefix+"Multiply", args, arithMultiplySettings)
+	if e != nil {
+		panic(e)
+	}
+	r0, _ = results[0].Interface().(int)
+	return
+}
+
+var arithDivideSettings = &rpc.InvokeSettings{
+	Idempotent: true,
+	Retry:      2,
+	Timeout:    5000000000, // 5s
+	ResultTypes: []reflect.Type{
+		reflect.TypeOf((**Quotient)(nil)).Elem(),
+	},
+}
+
+// Divide invokes the remote method Divide
+func (c *ArithClient) Divide(a0 *Args) (r0 *Quotient, err error) {
+	args := []reflect.Value{
+		reflect.ValueOf(&a0).Elem(),
+	}
+	results, e := c.client.Invoke(c.prefix+"Divide", args, arithDivideSettings)
+	if e != nil {
+		err = e
+		return
+	}
+	r0, _ = results[0].Interface().(*Quotient)
+	return
+}
+
+var arithSumSettings = &rpc.InvokeSettings{
+	ResultTypes: []reflect.Type{
+		reflect.TypeOf((*float64)(nil)).Elem(),
+	},
+}
+
+// Sum invokes the remote method Sum
+func (c *ArithClient) Sum(a0 float64, a1 ...int) (r0 float64) {
+	args := []reflect.Value{
+		reflect.ValueOf(&a0).Elem(),
+	}
+	for i := range a1 {
+		args = append(args, reflect.ValueOf(&a1[i]).Elem())
+	}
+	results, e := c.client.Invoke(c.prefix+"Sum", args, arithSumSettings)
+	if e != nil {
+		panic(e)
+	}
+	r0, _ = results[0].Interface().(float64)
+	return
+}
+
+var arithNowSettings = &rpc.InvokeSettings{
+	ResultTypes: []reflect.Type{
+		reflect.TypeOf((*time.Time)(nil)).Elem(),
+	},
+}
+
+// Now invokes the remote method Now
+func (c *ArithClient) Now(ctx context.Context, a0 string) (r0 time.Time, err error) {
+	args := []reflect.Value{
+		reflect.ValueOf(&a0).Elem(),
+	}
+	results, e := c.client.InvokeContext(ctx, c.prefix+"Now", args, arithNowSettings)
+	if e != nil {
+		err = e
+		return
+	}
+	r0, _ = results[0].Interface().(time.Time)
+	return
+}
+
+var arithPairSettings = &rpc.InvokeSettings{
+	ResultTypes: []reflect.Type{
+		reflect.TypeOf((*string)(nil)).Elem(),
+		reflect.TypeOf((*int)(nil)).Elem(),
+	},
+}
+
+// Pair invokes the remote method pair
+func (c *ArithClient) Pair(a0 []string, a1 map[string]int) (r0 string, r1 int) {
+	args := []reflect.Value{
+		reflect.ValueOf(&a0).Elem(),
+		reflect.ValueOf(&a1).Elem(),
+	}
+	results, e := c.client.Invoke(c.prefix+"pair", args, arithPairSettings)
+	if e != nil {
+		panic(e)
+	}
+	r0, _ = results[0].Interface().(string)
+	r1, _ = results[1].Interface().(int)
+	return
+}
+
+var arithNothingSettings = &rpc.InvokeSettings{}
+
+// Nothing invokes the remote method Nothing
+func (c *ArithClient) Nothing() {
+	_, e := c.client.Invoke(c.prefix+"Nothing", nil, arithNothingSettings)
+	if e != nil {
+		panic(e)
+	}
+	return
+}
+
+type arithMultiplyInvoker struct {
+	impl Arith
+}
+
+func (invoker arithMultiplyInvoker) ReadArguments(reader *hio.Reader, count int) []reflect.Value {
+	var a0 int
+	var a1 int
+	args := make([]reflect.Value, 2)
+	if count > 2 {
+		args = make([]reflect.Value, count)
+	}
+	reader.ReadSliceFunc(count, func(i int) {
+		switch i {
+		case 0:
+			a0 = int(reader.ReadInt())
+		case 1:
+			a1 = int(reader.ReadInt())
+		default:
+			var v interface{}
+			reader.Unserialize(&v)
+			args[i] = reflect.ValueOf(&v).Elem()
+		}
+	})
+	args[0] = reflect.ValueOf(&a0).Elem()
+	args[1] = reflect.ValueOf(&a1).Elem()
+	return args
+}
+
+func (invoker arithMultiplyInvoker) Invoke(args []reflect.Value) ([]reflect.Value, error) {
+	a0, _ := args[0].Interface().(int)
+	a1, _ := args[1].Interface().(int)
+	r0 := invoker.impl.Multiply(a0, a1)
+	return []reflect.Value{reflect.ValueOf(&r0).Elem()}, nil
+}
+
+type arithDivideInvoker struct {
+	impl Arith
+}
+
+func (invoker arithDivideInvoker) ReadArguments(reader *hio.Reader, count int) []reflect.Value {
+	var a0 *Args
+	args := make([]reflect.Value, 1)
+	if count > 1 {
+		args = make([]reflect.Value, count)
+	}
+	reader.ReadSliceFunc(count, func(i int) {
+		switch i {
+		case 0:
+			reader.Unserialize(&a0)
+		default:
+			var v interface{}
+			reader.Unserialize(&v)
+			args[i] = reflect.ValueOf(&v).Elem()
+		}
+	})
+	args[0] = reflect.ValueOf(&a0).Elem()
+	return args
+}
+
+func (invoker arithDivideInvoker) Invoke(args []reflect.Value) ([]reflect.Value, error) {
+	a0, _ := args[0].Interface().(*Args)
+	r0, err := invoker.impl.Divide(a0)
+	return []reflect.Value{reflect.ValueOf(&r0).Elem()}, err
+}
+
+type arithSumInvoker struct {
+	impl Arith
+}
+
+func (invoker arithSumInvoker) ReadArguments(reader *hio.Reader, count int) []reflect.Value {
+	var a0 float64
+	args := make([]reflect.Value, 1)
+	if count > 1 {
+		args = make([]reflect.Value, count)
+	}
+	reader.ReadSliceFunc(count, func(i int) {
+		switch i {
+		case 0:
+			a0 = reader.ReadFloat64()
+		default:
+			var v int
+			v = int(reader.ReadInt())
+			args[i] = reflect.ValueOf(&v).Elem()
+		}
+	})
+	args[0] = reflect.ValueOf(&a0).Elem()
+	return args
+}
+
+func (invoker arithSumInvoker) Invoke(args []reflect.Value) ([]reflect.Value, error) {
+	a0, _ := args[0].Interface().(float64)
+	a1 := make([]int, len(args)-1)
+	for i := range a1 {
+		a1[i], _ = args[1+i].Interface().(int)
+	}
+	r0 := invoker.impl.Sum(a0, a1...)
+	return []reflect.Value{reflect.ValueOf(&r0).Elem()}, nil
+}
+
+type arithNowInvoker struct {
+	impl Arith
+}
+
+func (invoker arithNowInvoker) ReadArguments(reader *hio.Reader, count int) []reflect.Value {
+	var a0 string
+	args := make([]reflect.Value, 1)
+	if count > 1 {
+		args = make([]reflect.Value, count)
+	}
+	reader.ReadSliceFunc(count, func(i int) {
+		switch i {
+		case 0:
+			a0 = reader.ReadString()
+		default:
+			var v interface{}
+			reader.Unserialize(&v)
+			args[i] = reflect.ValueOf(&v).Elem()
+		}
+	})
+	args[0] = reflect.ValueOf(&a0).Elem()
+	return args
+}
+
+func (invoker arithNowInvoker) Invoke(args []reflect.Value) ([]reflect.Value, error) {
+	a0, _ := args[0].Interface().(string)
+	r0, err := invoker.impl.Now(context.Background(), a0)
+	return []reflect.Value{reflect.ValueOf(&r0).Elem()}, err
+}
+
+type arithPairInvoker struct {
+	impl Arith
+}
+
+func (invoker arithPairInvoker) ReadArguments(reader *hio.Reader, count int) []reflect.Value {
+	var a0 []string
+	var a1 map[string]int
+	args := make([]reflect.Value, 2)
+	if count > 2 {
+		args = make([]reflect.Value, count)
+	}
+	reader.ReadSliceFunc(count, func(i int) {
+		switch i {
+		case 0:
+			reader.Unserialize(&a0)
+		case 1:
+			reader.Unserialize(&a1)
+		default:
+			var v interface{}
+			reader.Unserialize(&v)
+			args[i] = reflect.ValueOf(&v).Elem()
+		}
+	})
+	args[0] = reflect.ValueOf(&a0).Elem()
+	args[1] = reflect.ValueOf(&a1).Elem()
+	return args
+}
+
+func (invoker arithPairInvoker) Invoke(args []reflect.Value) ([]reflect.Value, error) {
+	a0, _ := args[0].Interface().([]string)
+	a1, _ := args[1].Interface().(map[string]int)
+	r0, r1 := invoker.impl.Pair(a0, a1)
+	return []reflect.Value{reflect.ValueOf(&r0).Elem(), reflect.ValueOf(&r1).Elem()}, nil
+}
+
+type arithNothingInvoker struct {
+	impl Arith
+}
+
+func (invoker arithNothingInvoker) ReadArguments(reader *hio.Reader, count int) []reflect.Value {
+	args := make([]reflect.Value, 0)
+	if count > 0 {
+		args = make([]reflect.Value, count)
+	}
+	reader.ReadSliceFunc(count, func(i int) {
+		switch i {
+		default:
+			var v interface{}
+			reader.Unserialize(&v)
+			args[i] = reflect.ValueOf(&v).Elem()
+		}
+	})
+	return args
+}
+
+func (invoker arithNothingInvoker) Invoke(args []reflect.Value) ([]reflect.Value, error) {
+	invoker.impl.Nothing()
+	return nil, nil
+}
+
+// RegisterArithService publishes the methods of impl to service,
+// the methods are invoked without reflection.
+func RegisterArithService(service rpc.Service, impl Arith, options rpc.Options) {
+	service.AddInvoker("Multiply", arithMultiplyInvoker{impl}, options)
+	service.AddInvoker("Divide", arithDivideInvoker{impl}, options)
+	service.AddInvoker("Sum", arithSumInvoker{impl}, options)
+	service.AddInvoker("Now", arithNowInvoker{impl}, options)
+	service.AddInvoker("pair", arithPairInvoker{impl}, options)
+	service.AddInvoker("Nothing", arithNothingInvoker{impl}, options)
+}
@@ -0,0 +1,130 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * cmd/hprose-gen/internal/example/arith_test.go          *
+ *                                                        *
+ * hprose-gen example test for Go.                        *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package example
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hprose/hprose-golang/rpc"
+)
+
+var now = time.Date(2016, 10, 1, 12, 30, 0, 0, time.UTC)
+
+type arith struct {
+	nothing chan struct{}
+}
+
+func (arith) Multiply(a, b int) int {
+	return a * b
+}
+
+func (arith) Divide(args *Args) (*Quotient, error) {
+	if args.B == 0 {
+		return nil, errors.New("divide by zero")
+	}
+	return &Quotient{args.A / args.B, args.A % args.B}, nil
+}
+
+func (arith) Sum(base float64, nums ...int) float64 {
+	for _, n := range nums {
+		base += float64(n)
+	}
+	return base
+}
+
+func (arith) Now(ctx context.Context, name string) (time.Time, error) {
+	if name != "UTC" {
+		return time.Time{}, errors.New("unknown location: " + name)
+	}
+	return now, nil
+}
+
+func (arith) Pair(s []string, m map[string]int) (string, int) {
+	return s[len(s)-1], m[s[len(s)-1]]
+}
+
+func (a arith) Nothing() {
+	a.nothing <- struct{}{}
+}
+
+func testArith(t *testing.T, register func(server *rpc.TCPServer, impl Arith)) {
+	impl := arith{make(chan struct{}, 1)}
+	server := rpc.NewTCPServer("")
+	server.ErrorDelay = 0
+	register(server, impl)
+	server.Handle()
+	defer server.Close()
+	client := rpc.NewTCPClient(server.URI())
+	defer client.Close()
+	client.SetRetry(0)
+	var c Arith = NewArithClient(client)
+	if r := c.Multiply(6, 7); r != 42 {
+		t.Errorf("Multiply(6, 7) = %d, want 42", r)
+	}
+	if q, err := c.Divide(&Args{17, 5}); err != nil || *q != (Quotient{3, 2}) {
+		t.Errorf("Divide(17, 5) = %v, %v, want {3 2}", q, err)
+	}
+	if _, err := c.Divide(&Args{1, 0}); err == nil ||
+		err.Error() != "divide by zero" {
+		t.Errorf("Divide(1, 0) returns %v, want divide by zero", err)
+	}
+	if r := c.Sum(0.5); r != 0.5 {
+		t.Errorf("Sum(0.5) = %v, want 0.5", r)
+	}
+	if r := c.Sum(0.5, 1, 2, 3); r != 6.5 {
+		t.Errorf("Sum(0.5, 1, 2, 3) = %v, want 6.5", r)
+	}
+	if r, err := c.Now(context.Background(), "UTC"); err != nil ||
+		!r.Equal(now) {
+		t.Errorf("Now(UTC) = %v, %v, want %v", r, err, now)
+	}
+	s, n := c.Pair([]string{"a", "b"}, map[string]int{"a": 1, "b": 2})
+	if s != "b" || n != 2 {
+		t.Errorf("Pair = %s, %d, want b, 2", s, n)
+	}
+	c.Nothing()
+	select {
+	case <-impl.nothing:
+	case <-time.After(time.Second):
+		t.Error("Nothing isn't invoked")
+	}
+}
+
+func TestGeneratedService(t *testing.T) {
+	testArith(t, func(server *rpc.TCPServer, impl Arith) {
+		RegisterArithService(server, impl, rpc.Options{})
+	})
+}
+
+func TestReflectionService(t *testing.T) {
+	testArith(t, func(server *rpc.TCPServer, impl Arith) {
+		server.AddMethods([]string{
+			"Multiply", "Divide", "Sum", "Nothing",
+		}, impl, rpc.Options{})
+		server.AddMethod("Pair", impl, rpc.Options{}, "pair")
+		// the reflection service doesn't pass the context.Context argument.
+		server.AddFunction("Now", func(name string) (time.Time, error) {
+			return impl.Now(context.Background(), name)
+		}, rpc.Options{})
+	})
+}
@@ -0,0 +1,105 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * cmd/hprose-gen/main.go                                 *
+ *                                                        *
+ * hprose code generator for Go.                          *
+ *                                                        *
+ * LastModified: Oct 17, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+// Command hprose-gen generates the reflection-free hprose client and service
+// code for the Go interfaces.
+//
+// Usage:
+//
+//	hprose-gen [flags] file.go
+//
+// For every interface, such as:
+//
+//	type Arith interface {
+//		Multiply(a, b int) int
+//		// hprose:idempotent hedge=50ms
+//		Divide(args *Args) (*Quotient, error)
+//	}
+//
+// It generates the client type ArithClient with the constructor
+// NewArithClient(client rpc.Client, namespace ...string), which implements
+// Arith by calling client.Invoke with the precomputed InvokeSettings, and the
+// function RegisterArithService(service rpc.Service, impl Arith,
+// options rpc.Options), which publishes the methods of impl by the invokers
+// reading the arguments without reflection. They are wire-compatible with
+// UseService and AddInstanceMethods.
+//
+// The "hprose:" line in the doc comment of the method sets the invoke settings
+// of the method, the keys are the same as the tags of the UseService stub:
+// name, byref, simple, idempotent, failswitch, oneway, jsoncompat, coalesce,
+// retry, timeout, hedge, cache and result.
+//
+// It can be used with go generate:
+//
+//	//go:generate hprose-gen -type Arith arith.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+var (
+	typeNames = flag.String("type", "", "comma-separated list of interface names; default all interfaces")
+	output    = flag.String("o", "", "output file name; default <file>_hprose.go")
+	client    = flag.Bool("client", true, "generate the client code")
+	service   = flag.Bool("service", true, "generate the service code")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: hprose-gen [flags] file.go\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() != 1 {
+		usage()
+		os.Exit(2)
+	}
+	filename := flag.Arg(0)
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		fail(err)
+	}
+	var names []string
+	if *typeNames != "" {
+		names = strings.Split(*typeNames, ",")
+	}
+	code, err := generate(filename, src, names, *client, *service)
+	if err != nil {
+		fail(err)
+	}
+	out := *output
+	if out == "" {
+		out = strings.TrimSuffix(filename, ".go") + "_hprose.go"
+	}
+	if err = ioutil.WriteFile(out, code, 0644); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "hprose-gen:", err)
+	os.Exit(1)
+}
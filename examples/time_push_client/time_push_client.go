@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hprose/hprose-golang/rpc"
+)
+
+func main() {
+	client := rpc.NewTCPClient("tcp4://127.0.0.1:2016/")
+	done := make(chan bool)
+	count := 0
+	err := client.Subscribe("time", "", func(t string) {
+		fmt.Println(t)
+		count++
+		if count == 10 {
+			client.Unsubscribe("time")
+			done <- true
+		}
+	}, nil)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	<-done
+}
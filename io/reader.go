@@ -12,7 +12,7 @@
  *                                                        *
  * hprose reader for Go.                                  *
  *                                                        *
- * LastModified: Oct 8, 2016                              *
+ * LastModified: Oct 17, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -296,6 +296,18 @@ func (r *Reader) ReadSlice(v []reflect.Value) {
 	r.readByte()
 }
 
+// ReadSliceFunc reads count elements of the slice by calling read with the
+// index of every element, the count must be read by ReadCount before.
+func (r *Reader) ReadSliceFunc(count int, read func(i int)) {
+	if !r.Simple {
+		setReaderRef(r, nil)
+	}
+	for i := 0; i < count; i++ {
+		read(i)
+	}
+	r.readByte()
+}
+
 // ReadCount of array, slice, map or struct field
 func (r *Reader) ReadCount() int {
 	return int(ReadInt64(&r.ByteReader, TagOpenbrace))
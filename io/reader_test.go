@@ -12,7 +12,7 @@
  *                                                        *
  * hprose Reader Test for Go.                             *
  *                                                        *
- * LastModified: Sep 10, 2016                             *
+ * LastModified: Oct 18, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -1542,6 +1542,42 @@ func TestUnserializeList(t *testing.T) {
 	}
 }
 
+func TestReadSliceFunc(t *testing.T) {
+	for _, simple := range []bool{false, true} {
+		w := NewWriter(simple)
+		w.Serialize([]interface{}{"hello", "hello", 3})
+		w.Serialize("hello")
+		reader := NewReader(w.Bytes(), simple)
+		if tag := reader.readByte(); tag != TagList {
+			t.Fatal(simple, tag, TagList)
+		}
+		count := reader.ReadCount()
+		var indexes []int
+		var s0, s1 string
+		var i2 int64
+		reader.ReadSliceFunc(count, func(i int) {
+			indexes = append(indexes, i)
+			switch i {
+			case 0:
+				s0 = reader.ReadString()
+			case 1:
+				s1 = reader.ReadString()
+			case 2:
+				i2 = reader.ReadInt()
+			}
+		})
+		if !reflect.DeepEqual(indexes, []int{0, 1, 2}) {
+			t.Error(simple, indexes)
+		}
+		if s0 != "hello" || s1 != "hello" || i2 != 3 {
+			t.Error(simple, s0, s1, i2)
+		}
+		if s := reader.ReadString(); s != "hello" {
+			t.Error(simple, s, "hello")
+		}
+	}
+}
+
 func BenchmarkUnserializeList(b *testing.B) {
 	a := list.New()
 	a.PushBack(1)
@@ -0,0 +1,302 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/balancer.go                                        *
+ *                                                        *
+ * hprose load balancer for client.                       *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"hash/crc32"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Balancer chooses the service address for every request of the client.
+//
+// The methods of Balancer may be called concurrently.
+type Balancer interface {
+	// SetURIList is called when the uri list of the client is changed.
+	SetURIList(uriList []string)
+	// Select returns the uri for the request.
+	Select(context *ClientContext) string
+	// Done is called when the request sent to the uri is finished. It is also
+	// called for the uri returned by Select which is not used, err is
+	// ErrCircuitOpen if the circuit of the uri is open, otherwise nil.
+	Done(uri string, err error)
+}
+
+type balancerURIList struct {
+	sync.RWMutex
+	uris []string
+}
+
+func (list *balancerURIList) SetURIList(uriList []string) {
+	uris := make([]string, len(uriList))
+	copy(uris, uriList)
+	list.Lock()
+	list.uris = uris
+	list.Unlock()
+}
+
+func (list *balancerURIList) getURIList() (uris []string) {
+	list.RLock()
+	uris = list.uris
+	list.RUnlock()
+	return
+}
+
+// RoundRobinBalancer chooses the service addresses in turn.
+type RoundRobinBalancer struct {
+	balancerURIList
+	index uint32
+}
+
+// NewRoundRobinBalancer is the constructor of RoundRobinBalancer
+func NewRoundRobinBalancer() *RoundRobinBalancer {
+	return new(RoundRobinBalancer)
+}
+
+// Select returns the next uri
+func (balancer *RoundRobinBalancer) Select(context *ClientContext) string {
+	uris := balancer.getURIList()
+	n := uint32(len(uris))
+	if n == 0 {
+		return ""
+	}
+	return uris[(atomic.AddUint32(&balancer.index, 1)-1)%n]
+}
+
+// Done does nothing
+func (balancer *RoundRobinBalancer) Done(uri string, err error) {}
+
+// WeightedRandomBalancer chooses the service addresses randomly by weight.
+type WeightedRandomBalancer struct {
+	sync.RWMutex
+	weights map[string]int
+	uris    []string
+	totals  []int
+	rand    *rand.Rand
+	locker  sync.Mutex
+}
+
+// NewWeightedRandomBalancer is the constructor of WeightedRandomBalancer,
+// the weight of the uri which is not in weights is 1.
+func NewWeightedRandomBalancer(weights map[string]int) *WeightedRandomBalancer {
+	balancer := new(WeightedRandomBalancer)
+	balancer.weights = make(map[string]int, len(weights))
+	for uri, weight := range weights {
+		balancer.weights[uri] = weight
+	}
+	balancer.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	return balancer
+}
+
+// SetURIList set the uri list of the balancer
+func (balancer *WeightedRandomBalancer) SetURIList(uriList []string) {
+	uris := make([]string, 0, len(uriList))
+	totals := make([]int, 0, len(uriList))
+	total := 0
+	balancer.Lock()
+	for _, uri := range uriList {
+		weight, ok := balancer.weights[uri]
+		if !ok {
+			weight = 1
+		}
+		if weight > 0 {
+			total += weight
+			uris = append(uris, uri)
+			totals = append(totals, total)
+		}
+	}
+	balancer.uris = uris
+	balancer.totals = totals
+	balancer.Unlock()
+}
+
+// Select returns a random uri by weight
+func (balancer *WeightedRandomBalancer) Select(context *ClientContext) string {
+	balancer.RLock()
+	defer balancer.RUnlock()
+	n := len(balancer.uris)
+	if n == 0 {
+		return ""
+	}
+	balancer.locker.Lock()
+	r := balancer.rand.Intn(balancer.totals[n-1])
+	balancer.locker.Unlock()
+	return balancer.uris[sort.SearchInts(balancer.totals, r+1)]
+}
+
+// Done does nothing
+func (balancer *WeightedRandomBalancer) Done(uri string, err error) {}
+
+// LeastRequestsBalancer chooses the service address which has the least
+// outstanding requests.
+type LeastRequestsBalancer struct {
+	sync.Mutex
+	uris     []string
+	requests map[string]int
+	index    int
+}
+
+// NewLeastRequestsBalancer is the constructor of LeastRequestsBalancer
+func NewLeastRequestsBalancer() *LeastRequestsBalancer {
+	balancer := new(LeastRequestsBalancer)
+	balancer.requests = make(map[string]int)
+	return balancer
+}
+
+// SetURIList set the uri list of the balancer
+func (balancer *LeastRequestsBalancer) SetURIList(uriList []string) {
+	uris := make([]string, len(uriList))
+	copy(uris, uriList)
+	balancer.Lock()
+	balancer.uris = uris
+	balancer.Unlock()
+}
+
+// Select returns the uri which has the least outstanding requests
+func (balancer *LeastRequestsBalancer) Select(context *ClientContext) string {
+	balancer.Lock()
+	defer balancer.Unlock()
+	n := len(balancer.uris)
+	if n == 0 {
+		return ""
+	}
+	// starts from a rotating index, so the ties are chosen in turn.
+	balancer.index = (balancer.index + 1) % n
+	uri := balancer.uris[balancer.index]
+	for i := 1; i < n; i++ {
+		u := balancer.uris[(balancer.index+i)%n]
+		if balancer.requests[u] < balancer.requests[uri] {
+			uri = u
+		}
+	}
+	balancer.requests[uri]++
+	return uri
+}
+
+// Done decreases the outstanding requests of the uri
+func (balancer *LeastRequestsBalancer) Done(uri string, err error) {
+	balancer.Lock()
+	if n := balancer.requests[uri]; n > 1 {
+		balancer.requests[uri] = n - 1
+	} else {
+		delete(balancer.requests, uri)
+	}
+	balancer.Unlock()
+}
+
+// ConsistentHashBalancer chooses the service address by the consistent hash
+// of the key which is returned by the key func. The requests with the same key
+// are sent to the same service address, and the retried request is sent to
+// the next service address on the hash ring.
+type ConsistentHashBalancer struct {
+	sync.RWMutex
+	key      func(context *ClientContext) string
+	replicas int
+	hashes   []uint32
+	nodes    map[uint32]string
+	count    int
+}
+
+// NewConsistentHashBalancer is the constructor of ConsistentHashBalancer,
+// replicas is the number of virtual nodes for every uri on the hash ring,
+// the default value is 100.
+//
+// For example:
+//
+//	balancer := rpc.NewConsistentHashBalancer(func(context *rpc.ClientContext) string {
+//		userID, _ := context.Context().Value(userIDKey).(string)
+//		return userID
+//	}, 0)
+//	client.SetBalancer(balancer)
+//	ctx := context.WithValue(context.Background(), userIDKey, userID)
+//	client.InvokeContext(ctx, "getProfile", args, nil)
+func NewConsistentHashBalancer(
+	key func(context *ClientContext) string,
+	replicas int) *ConsistentHashBalancer {
+	if key == nil {
+		panic("key can't be nil")
+	}
+	if replicas <= 0 {
+		replicas = 100
+	}
+	return &ConsistentHashBalancer{key: key, replicas: replicas}
+}
+
+// SetURIList set the uri list of the balancer
+func (balancer *ConsistentHashBalancer) SetURIList(uriList []string) {
+	hashes := make([]uint32, 0, len(uriList)*balancer.replicas)
+	nodes := make(map[uint32]string, len(uriList)*balancer.replicas)
+	for _, uri := range uriList {
+		for i := 0; i < balancer.replicas; i++ {
+			hash := crc32.ChecksumIEEE([]byte(strconv.Itoa(i) + uri))
+			if _, ok := nodes[hash]; !ok {
+				hashes = append(hashes, hash)
+				nodes[hash] = uri
+			}
+		}
+	}
+	sort.Sort(uint32Slice(hashes))
+	balancer.Lock()
+	balancer.hashes = hashes
+	balancer.nodes = nodes
+	balancer.count = len(uriList)
+	balancer.Unlock()
+}
+
+// Select returns the uri by the consistent hash of the key
+func (balancer *ConsistentHashBalancer) Select(context *ClientContext) string {
+	hash := crc32.ChecksumIEEE([]byte(balancer.key(context)))
+	balancer.RLock()
+	defer balancer.RUnlock()
+	n := len(balancer.hashes)
+	if n == 0 {
+		return ""
+	}
+	i := sort.Search(n, func(i int) bool { return balancer.hashes[i] >= hash })
+	uri := balancer.nodes[balancer.hashes[i%n]]
+	// skips the uris which have been tried by the retried request.
+	skip := context.Retried % balancer.count
+	if skip == 0 {
+		return uri
+	}
+	tried := map[string]bool{uri: true}
+	for j := 1; j < n; j++ {
+		if u := balancer.nodes[balancer.hashes[(i+j)%n]]; !tried[u] {
+			tried[u] = true
+			if skip--; skip == 0 {
+				return u
+			}
+		}
+	}
+	return uri
+}
+
+// Done does nothing
+func (balancer *ConsistentHashBalancer) Done(uri string, err error) {}
+
+type uint32Slice []uint32
+
+func (s uint32Slice) Len() int           { return len(s) }
+func (s uint32Slice) Less(i, j int) bool { return s[i] < s[j] }
+func (s uint32Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,142 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/balancer_test.go                                   *
+ *                                                        *
+ * hprose balancer test for Go.                           *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"strconv"
+	"testing"
+)
+
+var testURIList = []string{"tcp://a", "tcp://b", "tcp://c"}
+
+func TestBalancerEmptyURIList(t *testing.T) {
+	balancers := map[string]Balancer{
+		"RoundRobin":     NewRoundRobinBalancer(),
+		"WeightedRandom": NewWeightedRandomBalancer(nil),
+		"LeastRequests":  NewLeastRequestsBalancer(),
+		"ConsistentHash": NewConsistentHashBalancer(
+			func(context *ClientContext) string { return "key" }, 0),
+	}
+	for name, balancer := range balancers {
+		if uri := balancer.Select(&ClientContext{}); uri != "" {
+			t.Errorf("%s selects %q from the empty uri list", name, uri)
+		}
+	}
+}
+
+func TestRoundRobinBalancer(t *testing.T) {
+	balancer := NewRoundRobinBalancer()
+	balancer.SetURIList(testURIList)
+	for i := 0; i < 6; i++ {
+		if uri := balancer.Select(&ClientContext{}); uri != testURIList[i%3] {
+			t.Errorf("select %d: %q, want %q", i, uri, testURIList[i%3])
+		}
+	}
+}
+
+func TestWeightedRandomBalancer(t *testing.T) {
+	balancer := NewWeightedRandomBalancer(map[string]int{
+		"tcp://a": 0,
+		"tcp://c": 3,
+	})
+	balancer.SetURIList(testURIList)
+	counts := make(map[string]int)
+	for i := 0; i < 4000; i++ {
+		counts[balancer.Select(&ClientContext{})]++
+	}
+	if counts["tcp://a"] != 0 {
+		t.Errorf("the uri with weight 0 is selected %d times", counts["tcp://a"])
+	}
+	if counts["tcp://b"]+counts["tcp://c"] != 4000 {
+		t.Fatalf("unexpected selections: %v", counts)
+	}
+	ratio := float64(counts["tcp://c"]) / float64(counts["tcp://b"])
+	if ratio < 2 || ratio > 4 {
+		t.Errorf("the ratio of weight 3 to weight 1 is %v", ratio)
+	}
+}
+
+func TestLeastRequestsBalancer(t *testing.T) {
+	balancer := NewLeastRequestsBalancer()
+	balancer.SetURIList(testURIList)
+	selected := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		selected[balancer.Select(&ClientContext{})] = true
+	}
+	if len(selected) != 3 {
+		t.Fatalf("outstanding requests are not spread: %v", selected)
+	}
+	balancer.Done("tcp://b", nil)
+	for i := 0; i < 3; i++ {
+		if uri := balancer.Select(&ClientContext{}); uri != "tcp://b" {
+			t.Fatalf("select %q, want tcp://b", uri)
+		}
+		balancer.Done("tcp://b", nil)
+	}
+}
+
+func TestConsistentHashBalancer(t *testing.T) {
+	key := func(context *ClientContext) string { return context.method }
+	balancer := NewConsistentHashBalancer(key, 0)
+	balancer.SetURIList(testURIList)
+	selected := make(map[string]string)
+	for i := 0; i < 300; i++ {
+		context := &ClientContext{method: "key" + strconv.Itoa(i)}
+		uri := balancer.Select(context)
+		if balancer.Select(context) != uri {
+			t.Fatalf("the same key %q is sent to different uris", context.method)
+		}
+		tried := map[string]bool{uri: true}
+		for context.Retried = 1; context.Retried < 3; context.Retried++ {
+			retryURI := balancer.Select(context)
+			if tried[retryURI] {
+				t.Fatalf("the retried request of %q is sent to %q again",
+					context.method, retryURI)
+			}
+			tried[retryURI] = true
+		}
+		selected[context.method] = uri
+	}
+	counts := make(map[string]int)
+	for _, uri := range selected {
+		counts[uri]++
+	}
+	if len(counts) != 3 {
+		t.Fatalf("the keys are not spread: %v", counts)
+	}
+	balancer.SetURIList(testURIList[:2])
+	for method, uri := range selected {
+		if uri == "tcp://c" {
+			continue
+		}
+		if newURI := balancer.Select(&ClientContext{method: method}); newURI != uri {
+			t.Errorf("the key %q is remapped from %q to %q", method, uri, newURI)
+		}
+	}
+}
+
+func TestConsistentHashBalancerNilKey(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewConsistentHashBalancer(nil, 0) doesn't panic")
+		}
+	}()
+	NewConsistentHashBalancer(nil, 0)
+}
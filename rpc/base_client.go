@@ -12,7 +12,7 @@
  *                                                        *
  * hprose rpc base client for Go.                         *
  *                                                        *
- * LastModified: Oct 7, 2016                              *
+ * LastModified: Oct 18, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -20,8 +20,8 @@
 package rpc
 
 import (
+	"context"
 	"crypto/tls"
-	"errors"
 	"fmt"
 	"io"
 	"math/rand"
@@ -29,7 +29,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
-	"sync/atomic"
+	"sync"
 	"time"
 
 	hio "github.com/hprose/hprose-golang/io"
@@ -42,20 +42,50 @@ type BaseClient struct {
 	filterManager
 	uri            string
 	uriList        []string
-	index          int32
+	index          int
 	failround      int
+	uriLocker      sync.RWMutex
+	resolver       Resolver
+	resolverLocker sync.Mutex
+	setURIList     func(uriList []string)
+	closeURIs      func(uriList []string)
+	balancer       Balancer
+	breaker        *CircuitBreaker
+	healthChecker  *HealthChecker
 	retry          int
+	retryPolicy    RetryPolicy
+	retryBudget    *RetryBudget
+	metrics        MetricsCollector
+	rateLimiter    RateLimiter
+	methodLimiters map[string]RateLimiter
+	limiterLocker  sync.RWMutex
+	failFast       bool
+	cache          *ResponseCache
+	flights        flightGroup
 	timeout        time.Duration
+	strictService  bool
 	event          ClientEvent
 	contextPool    chan *ClientContext
+	id             string
+	topics         map[string]map[string]*clientTopic
+	topicLocker    sync.RWMutex
+	batch          *batch
+	batchLocker    sync.Mutex
 	SendAndReceive func([]byte, *ClientContext) ([]byte, error)
 }
 
+// subscribeTimeout is the default timeout of the push topic polling request,
+// it is longer than the default topic timeout of BaseService.
+const subscribeTimeout = 5 * time.Minute
+
 func (client *BaseClient) initBaseClient() {
 	client.initHandlerManager()
 	client.timeout = 30 * 1000 * 1000 * 1000
 	client.retry = 10
 	client.contextPool = make(chan *ClientContext, runtime.NumCPU())
+	client.topics = make(map[string]map[string]*clientTopic)
+	client.cache = NewResponseCache(DefaultResponseCacheSize)
+	client.setURIList = client.SetURIList
 	client.override.invokeHandler = func(
 		name string, args []reflect.Value,
 		context Context) (results []reflect.Value, err error) {
@@ -71,8 +101,14 @@ func (client *BaseClient) initBaseClient() {
 	}
 }
 
+func (client *BaseClient) baseClient() *BaseClient {
+	return client
+}
+
 // URI returns the current hprose service address.
 func (client *BaseClient) URI() string {
+	client.uriLocker.RLock()
+	defer client.uriLocker.RUnlock()
 	return client.uri
 }
 
@@ -86,6 +122,8 @@ func (client *BaseClient) SetURI(uri string) {
 
 // URIList returns all of the hprose service addresses
 func (client *BaseClient) URIList() []string {
+	client.uriLocker.RLock()
+	defer client.uriLocker.RUnlock()
 	return client.uriList
 }
 
@@ -99,12 +137,281 @@ func shuffleStringSlice(src []string) []string {
 	return dest
 }
 
-// SetURIList set a list of server addresses
+// SetURIList set a list of server addresses.
+//
+// The current service address is kept if it is in the new list, the pooled
+// connections to the removed service addresses are drained.
 func (client *BaseClient) SetURIList(uriList []string) {
-	client.uriList = shuffleStringSlice(uriList)
-	client.index = 0
+	uriList = shuffleStringSlice(uriList)
+	client.uriLocker.Lock()
+	removed := subtractStringSlice(client.uriList, uriList)
+	index := 0
+	for i, uri := range uriList {
+		if uri == client.uri {
+			index = i
+			break
+		}
+	}
+	client.uriList = uriList
+	client.index = index
 	client.failround = 0
-	client.uri = client.uriList[0]
+	client.uri = uriList[index]
+	client.uriLocker.Unlock()
+	if breaker := client.breaker; breaker != nil {
+		breaker.setURIList(uriList)
+	}
+	if checker := client.healthChecker; checker != nil {
+		checker.setURIList(uriList)
+	}
+	if balancer := client.balancer; balancer != nil {
+		balancer.SetURIList(client.availableURIList())
+	}
+	if len(removed) > 0 && client.closeURIs != nil {
+		client.closeURIs(removed)
+	}
+}
+
+// subtractStringSlice returns the strings in a but not in b
+func subtractStringSlice(a []string, b []string) (result []string) {
+	set := make(map[string]bool, len(b))
+	for _, s := range b {
+		set[s] = true
+	}
+	for _, s := range a {
+		if !set[s] {
+			result = append(result, s)
+		}
+	}
+	return
+}
+
+// Resolver returns the service address resolver of hprose client
+func (client *BaseClient) Resolver() Resolver {
+	client.resolverLocker.Lock()
+	defer client.resolverLocker.Unlock()
+	return client.resolver
+}
+
+// SetResolver set the service address resolver of hprose client, the service
+// addresses are updated by the resolver until the client is closed or another
+// resolver is set. The previous resolver is closed.
+//
+// The failed updates are ignored and reported by the OnResolveError event,
+// the client keeps the last service addresses.
+func (client *BaseClient) SetResolver(resolver Resolver) (err error) {
+	var uriList []string
+	if resolver != nil {
+		uriList, err = resolver.Resolve(func(uriList []string, err error) {
+			client.resolved(resolver, uriList, err)
+		})
+		if err == nil && len(uriList) == 0 {
+			err = ErrNoServiceAddress
+		}
+		if err != nil {
+			resolver.Close()
+			return
+		}
+	}
+	client.resolverLocker.Lock()
+	old := client.resolver
+	client.resolver = resolver
+	client.resolverLocker.Unlock()
+	if old != nil && old != resolver {
+		old.Close()
+	}
+	if resolver != nil {
+		client.setURIList(uriList)
+	}
+	return nil
+}
+
+func (client *BaseClient) resolved(
+	resolver Resolver, uriList []string, err error) {
+	if client.Resolver() != resolver {
+		return
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			client.resolveError(NewPanicError(e))
+		}
+	}()
+	if err == nil && len(uriList) == 0 {
+		err = ErrNoServiceAddress
+	}
+	if err != nil {
+		client.resolveError(err)
+		return
+	}
+	client.setURIList(uriList)
+}
+
+func (client *BaseClient) resolveError(err error) {
+	if event, ok := client.event.(onResolveErrorEvent); ok {
+		event.OnResolveError(client, err)
+	}
+}
+
+// available returns true if the service address is neither open by the
+// circuit breaker nor unhealthy.
+func (client *BaseClient) available(uri string) bool {
+	if breaker := client.breaker; breaker != nil && !breaker.available(uri) {
+		return false
+	}
+	if checker := client.healthChecker; checker != nil && !checker.Healthy(uri) {
+		return false
+	}
+	return true
+}
+
+// availableURIList returns the service addresses which are available.
+func (client *BaseClient) availableURIList() []string {
+	all := client.URIList()
+	if client.breaker == nil && client.healthChecker == nil {
+		return all
+	}
+	uriList := make([]string, 0, len(all))
+	for _, uri := range all {
+		if client.available(uri) {
+			uriList = append(uriList, uri)
+		}
+	}
+	return uriList
+}
+
+// Balancer returns the load balancer of hprose client
+func (client *BaseClient) Balancer() Balancer {
+	return client.balancer
+}
+
+// SetBalancer set the load balancer of hprose client.
+//
+// The balancer chooses the service address for every request, if it is nil,
+// the requests are sent to the current service address, which is only changed
+// by failswitch.
+func (client *BaseClient) SetBalancer(balancer Balancer) {
+	client.balancer = balancer
+	if balancer != nil {
+		balancer.SetURIList(client.availableURIList())
+	}
+}
+
+// CircuitBreaker returns the circuit breaker of hprose client
+func (client *BaseClient) CircuitBreaker() *CircuitBreaker {
+	return client.breaker
+}
+
+// SetCircuitBreaker set the circuit breaker of hprose client.
+//
+// The open service addresses are skipped by the balancer and failswitch.
+func (client *BaseClient) SetCircuitBreaker(breaker *CircuitBreaker) {
+	if breaker != nil {
+		breaker.changed = client.breakerChanged
+		breaker.probe = func(uri string) error {
+			return client.probe(uri, "#", 0)
+		}
+		breaker.setURIList(client.URIList())
+	}
+	client.breaker = breaker
+	if client.balancer != nil {
+		client.balancer.SetURIList(client.availableURIList())
+	}
+}
+
+// HealthChecker returns the health checker of hprose client
+func (client *BaseClient) HealthChecker() *HealthChecker {
+	return client.healthChecker
+}
+
+// SetHealthChecker set the health checker of hprose client, and starts the
+// probes. The previous health checker is stopped. The zero fields of checker
+// are set to the defaults.
+//
+// The unhealthy service addresses are skipped by the balancer and failswitch.
+func (client *BaseClient) SetHealthChecker(checker *HealthChecker) {
+	if old := client.healthChecker; old != nil {
+		old.stop()
+	}
+	if checker != nil {
+		checker.init()
+		checker.changed = client.healthChanged
+		checker.probe = func(uri string) error {
+			return client.probe(uri, checker.Method, checker.Timeout)
+		}
+		checker.setURIList(client.URIList())
+	}
+	client.healthChecker = checker
+	if client.balancer != nil {
+		client.balancer.SetURIList(client.availableURIList())
+	}
+	if checker != nil {
+		checker.start()
+	}
+}
+
+func (client *BaseClient) healthChanged(uri string, healthy bool) {
+	client.availabilityChanged(uri, healthy)
+	if healthy {
+		if event, ok := client.event.(onHealthyEvent); ok {
+			event.OnHealthy(client, uri)
+		}
+	} else if event, ok := client.event.(onUnhealthyEvent); ok {
+		event.OnUnhealthy(client, uri)
+	}
+}
+
+// availabilityChanged updates the service addresses of the balancer, and
+// switches the current service address if it becomes unavailable.
+func (client *BaseClient) availabilityChanged(uri string, available bool) {
+	balancer := client.balancer
+	if balancer != nil {
+		balancer.SetURIList(client.availableURIList())
+	} else if !available && uri == client.URI() {
+		client.failswitch()
+	}
+}
+
+func (client *BaseClient) breakerChanged(uri string, state BreakerState) {
+	client.availabilityChanged(uri, state != BreakerOpen)
+	switch state {
+	case BreakerOpen:
+		if event, ok := client.event.(onBreakerOpenEvent); ok {
+			event.OnBreakerOpen(client, uri)
+		}
+	case BreakerClosed:
+		if event, ok := client.event.(onBreakerCloseEvent); ok {
+			event.OnBreakerClose(client, uri)
+		}
+	}
+}
+
+// probe the service address by calling the remote method, the built-in "#"
+// function returns the client id.
+func (client *BaseClient) probe(
+	uri string, method string, timeout time.Duration) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = NewPanicError(e)
+		}
+	}()
+	settings := &InvokeSettings{
+		Simple:     true,
+		Idempotent: true,
+		Timeout:    timeout,
+	}
+	if method == "#" {
+		settings.ResultTypes = []reflect.Type{stringType}
+	}
+	ctx := context.Background()
+	clientContext := new(ClientContext)
+	client.initClientContext(ctx, clientContext, settings)
+	clientContext.uri = uri
+	request := client.encode(method, nil, clientContext)
+	response, err := client.handlerManager.beforeFilterHandler(
+		request, clientContext)
+	if err == nil {
+		_, err = client.decode(response, nil, clientContext)
+	}
+	return
 }
 
 // TLSClientConfig returns the tls config of hprose client
@@ -125,6 +432,117 @@ func (client *BaseClient) SetRetry(value int) {
 	client.retry = value
 }
 
+// RetryPolicy returns the retry policy of hprose client
+func (client *BaseClient) RetryPolicy() RetryPolicy {
+	return client.retryPolicy
+}
+
+// SetRetryPolicy set the retry policy of hprose client, it can be overridden
+// by InvokeSettings.RetryPolicy. If it is nil, the Idempotent request is
+// retried after a linear growing interval.
+func (client *BaseClient) SetRetryPolicy(policy RetryPolicy) {
+	client.retryPolicy = policy
+}
+
+// RetryBudget returns the retry budget of hprose client
+func (client *BaseClient) RetryBudget() *RetryBudget {
+	return client.retryBudget
+}
+
+// SetRetryBudget set the retry budget of hprose client
+func (client *BaseClient) SetRetryBudget(budget *RetryBudget) {
+	client.retryBudget = budget
+}
+
+// RateLimiter returns the rate limiter of all the requests
+func (client *BaseClient) RateLimiter() RateLimiter {
+	return client.rateLimiter
+}
+
+// SetRateLimiter set the rate limiter of all the requests
+func (client *BaseClient) SetRateLimiter(limiter RateLimiter) {
+	client.rateLimiter = limiter
+}
+
+// MethodRateLimiter returns the rate limiter of the remote method
+func (client *BaseClient) MethodRateLimiter(method string) RateLimiter {
+	client.limiterLocker.RLock()
+	defer client.limiterLocker.RUnlock()
+	return client.methodLimiters[strings.ToLower(method)]
+}
+
+// SetMethodRateLimiter set the rate limiter of the remote method, the requests
+// of the method are limited by both of it and the RateLimiter. The limiter is
+// removed if it is nil.
+func (client *BaseClient) SetMethodRateLimiter(
+	method string, limiter RateLimiter) {
+	method = strings.ToLower(method)
+	client.limiterLocker.Lock()
+	defer client.limiterLocker.Unlock()
+	if limiter == nil {
+		delete(client.methodLimiters, method)
+		return
+	}
+	if client.methodLimiters == nil {
+		client.methodLimiters = make(map[string]RateLimiter)
+	}
+	client.methodLimiters[method] = limiter
+}
+
+// RateLimitFailFast returns true if the request exceeding the rate limit fails
+// with RateLimitError, otherwise it waits for the rate limiter.
+func (client *BaseClient) RateLimitFailFast() bool {
+	return client.failFast
+}
+
+// SetRateLimitFailFast set whether the request exceeding the rate limit fails
+// with RateLimitError immediately or waits until it is allowed.
+func (client *BaseClient) SetRateLimitFailFast(value bool) {
+	client.failFast = value
+}
+
+// rateLimit takes the permits of the rate limiters for the request
+func (client *BaseClient) rateLimit(context *ClientContext) error {
+	limiters := [...]RateLimiter{nil, client.rateLimiter}
+	if context.method != "" {
+		limiters[0] = client.MethodRateLimiter(context.method)
+	}
+	for _, limiter := range limiters {
+		if limiter == nil {
+			continue
+		}
+		if client.failFast {
+			if !limiter.Allow() {
+				return &RateLimitError{context.method}
+			}
+		} else if err := limiter.Wait(context.ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResponseCache returns the response cache of hprose client
+func (client *BaseClient) ResponseCache() *ResponseCache {
+	return client.cache
+}
+
+// SetResponseCache set the response cache of hprose client, the responses of
+// the invocations with InvokeSettings.Cache are cached in it.
+func (client *BaseClient) SetResponseCache(cache *ResponseCache) {
+	client.cache = cache
+}
+
+// Metrics returns the metrics collector of hprose client
+func (client *BaseClient) Metrics() MetricsCollector {
+	return client.metrics
+}
+
+// SetMetrics set the metrics collector of hprose client
+func (client *BaseClient) SetMetrics(metrics MetricsCollector) {
+	client.metrics = metrics
+}
+
 // Timeout returns the client timeout setting
 func (client *BaseClient) Timeout() time.Duration {
 	return client.timeout
@@ -135,8 +553,22 @@ func (client *BaseClient) SetTimeout(value time.Duration) {
 	client.timeout = value
 }
 
+// StrictService returns the strict mode of UseService
+func (client *BaseClient) StrictService() bool {
+	return client.strictService
+}
+
+// SetStrictService set the strict mode of UseService. In strict mode,
+// UseService fetches the remote function list and panics with an error
+// naming every stub field that has no matching remote method.
+func (client *BaseClient) SetStrictService(value bool) {
+	client.strictService = value
+}
+
 // Failround return the fail round
 func (client *BaseClient) Failround() int {
+	client.uriLocker.RLock()
+	defer client.uriLocker.RUnlock()
 	return client.failround
 }
 
@@ -207,9 +639,87 @@ func (client *BaseClient) UseService(remoteService interface{}, namespace ...str
 	if v.Kind() != reflect.Ptr {
 		panic("UseService: remoteService argument must be a pointer")
 	}
+	if client.strictService {
+		if err := client.checkRemoteService(v.Type().Elem(), ns); err != nil {
+			panic(err)
+		}
+	}
 	buildRemoteService(client, v, ns)
 }
 
+// FunctionList returns the names of the remote functions published by the
+// hprose service.
+func (client *BaseClient) FunctionList() (names []string, err error) {
+	settings := &InvokeSettings{
+		Simple:     true,
+		Idempotent: true,
+	}
+	ctx := context.Background()
+	clientContext := new(ClientContext)
+	client.initClientContext(ctx, clientContext, settings)
+	response, err := client.sendRequest([]byte{hio.TagEnd}, clientContext)
+	if err != nil {
+		return nil, err
+	}
+	return decodeFunctionList(response)
+}
+
+func decodeFunctionList(data []byte) (names []string, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = NewPanicError(e)
+		}
+	}()
+	n := len(data)
+	if n == 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if data[n-1] != hio.TagEnd {
+		return nil, fmt.Errorf("Wrong Response: \r\n%s", data)
+	}
+	reader := hio.NewReader(data, false)
+	tag, _ := reader.ReadByte()
+	switch tag {
+	case hio.TagFunctions:
+		reader.Unserialize(&names)
+		tag, _ = reader.ReadByte()
+	case hio.TagError:
+		return nil, &ServerError{reader.ReadString()}
+	}
+	if tag != hio.TagEnd {
+		return nil, fmt.Errorf("Wrong Response: \r\n%s", data)
+	}
+	return
+}
+
+// checkRemoteService returns an error naming every stub field of t that has
+// no matching remote method.
+func (client *BaseClient) checkRemoteService(t reflect.Type, ns string) error {
+	functions, err := client.FunctionList()
+	if err != nil {
+		return err
+	}
+	published := make(map[string]bool, len(functions))
+	for _, name := range functions {
+		published[strings.ToLower(name)] = true
+	}
+	if published["*"] {
+		return nil
+	}
+	var missing []string
+	for _, field := range getRemoteMethodFields(t, ns, "") {
+		if !published[strings.ToLower(field[1])] {
+			missing = append(missing, field[0]+" ("+field[1]+")")
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf(
+			"UseService: remote methods not found for stub fields: %s",
+			strings.Join(missing, ", "))
+	}
+	return nil
+}
+
 func (client *BaseClient) acquireContext() (context *ClientContext) {
 	select {
 	case context = <-client.contextPool:
@@ -227,10 +737,14 @@ func (client *BaseClient) releaseContext(context *ClientContext) {
 }
 
 func (client *BaseClient) initClientContext(
-	context *ClientContext, settings *InvokeSettings) {
+	ctx context.Context, context *ClientContext, settings *InvokeSettings) {
 	context.initBaseContext()
 	context.Client = client
 	context.Retried = 0
+	context.ctx = ctx
+	context.uri = ""
+	context.batched = false
+	context.method = ""
 	if settings == nil {
 		context.InvokeSettings = InvokeSettings{
 			Timeout: client.timeout,
@@ -249,9 +763,34 @@ func (client *BaseClient) initClientContext(
 
 // Invoke the remote method synchronous
 func (client *BaseClient) Invoke(name string, args []reflect.Value, settings *InvokeSettings) (results []reflect.Value, err error) {
+	return client.InvokeContext(context.Background(), name, args, settings)
+}
+
+// InvokeContext invoke the remote method synchronous with ctx, the invocation
+// is aborted when ctx is done.
+func (client *BaseClient) InvokeContext(ctx context.Context, name string, args []reflect.Value, settings *InvokeSettings) (results []reflect.Value, err error) {
+	return client.invokeContext(ctx, nil, name, args, settings)
+}
+
+func (client *BaseClient) invokeContext(
+	ctx context.Context, b *batch,
+	name string, args []reflect.Value,
+	settings *InvokeSettings) (results []reflect.Value, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	context := client.acquireContext()
-	client.initClientContext(context, settings)
+	client.initClientContext(ctx, context, settings)
+	context.batch = b
+	start := time.Now()
 	results, err = client.handlerManager.invokeHandler(name, args, context)
+	if metrics := client.metrics; metrics != nil {
+		metrics.Invoked(name, context.uri, time.Since(start), err)
+	}
+	if context.batch != nil {
+		context.batch.release()
+		context.batch = nil
+	}
 	if results == nil && len(context.ResultTypes) > 0 {
 		n := len(context.ResultTypes)
 		results = make([]reflect.Value, n)
@@ -259,12 +798,20 @@ func (client *BaseClient) Invoke(name string, args []reflect.Value, settings *In
 			results[i] = reflect.New(context.ResultTypes[i]).Elem()
 		}
 	}
+	context.ctx = nil
 	client.releaseContext(context)
 	return
 }
 
 // Go invoke the remote method asynchronous
 func (client *BaseClient) Go(name string, args []reflect.Value, callback Callback, settings *InvokeSettings) {
+	client.GoContext(context.Background(), name, args, callback, settings)
+}
+
+// GoContext invoke the remote method asynchronous with ctx, the invocation is
+// aborted when ctx is done.
+func (client *BaseClient) GoContext(ctx context.Context, name string, args []reflect.Value, callback Callback, settings *InvokeSettings) {
+	b := client.acquireBatch()
 	go func() {
 		defer func() {
 			if e := recover(); e != nil {
@@ -274,19 +821,282 @@ func (client *BaseClient) Go(name string, args []reflect.Value, callback Callbac
 				}
 			}
 		}()
-		callback(client.Invoke(name, args, settings))
+		callback(client.invokeContext(ctx, b, name, args, settings))
 	}()
 }
 
+// BeginBatch starts a batch, all the asynchronous invocations of the client
+// after BeginBatch are collected, and they are sent in one request when
+// EndBatch is called.
+//
+// The synchronous invocations are not batched, they are sent immediately.
+func (client *BaseClient) BeginBatch() {
+	client.batchLocker.Lock()
+	if client.batch == nil {
+		client.batch = newBatch()
+	}
+	client.batchLocker.Unlock()
+}
+
+// EndBatch sends the invocations collected after BeginBatch in one request,
+// and returns after their responses are dispatched.
+func (client *BaseClient) EndBatch() {
+	client.batchLocker.Lock()
+	b := client.batch
+	client.batch = nil
+	client.batchLocker.Unlock()
+	if b == nil {
+		return
+	}
+	if calls := b.wait(); len(calls) > 0 {
+		client.sendBatch(context.Background(), calls)
+	}
+}
+
+func (client *BaseClient) acquireBatch() *batch {
+	client.batchLocker.Lock()
+	b := client.batch
+	client.batchLocker.Unlock()
+	if b != nil && b.acquire() {
+		return b
+	}
+	return nil
+}
+
 // Close the client
-func (client *BaseClient) Close() {}
+func (client *BaseClient) Close() {
+	client.SetResolver(nil)
+	if checker := client.healthChecker; checker != nil {
+		checker.stop()
+	}
+}
+
+func (client *BaseClient) getID() (id string, err error) {
+	client.topicLocker.RLock()
+	id = client.id
+	client.topicLocker.RUnlock()
+	if id != "" {
+		return
+	}
+	settings := &InvokeSettings{
+		Simple:      true,
+		Idempotent:  true,
+		ResultTypes: []reflect.Type{stringType},
+	}
+	results, err := client.Invoke("#", nil, settings)
+	if err != nil {
+		return "", err
+	}
+	id = results[0].String()
+	client.topicLocker.Lock()
+	if client.id == "" {
+		client.id = id
+	} else {
+		id = client.id
+	}
+	client.topicLocker.Unlock()
+	return
+}
+
+// Subscribe a push topic which is published by the hprose service.
+//
+// If id is empty, the client id is fetched from the service by the built-in
+// "#" function, and it is shared by all the topics of this client.
+//
+// callback is a func, its parameters are the types of the pushed message, and
+// the last parameter can be an error to receive the polling errors,
+// for example:
+//
+//	client.Subscribe("time", "", func(t string, err error) {
+//		fmt.Println(t, err)
+//	}, nil)
+//
+// settings is used for the polling requests, if settings.Timeout is not set,
+// it is 5 minutes, which is longer than the default topic timeout of the
+// service. If settings.ResultTypes is set, the pushed message is decoded as
+// these types instead of the parameter types of callback, so they must be
+// assignable to the parameters of callback, or Subscribe panics.
+func (client *BaseClient) Subscribe(
+	topic string, id string,
+	callback interface{}, settings *InvokeSettings) (err error) {
+	cb := reflect.ValueOf(callback)
+	if cb.Kind() != reflect.Func {
+		panic("Subscribe: callback argument must be a func")
+	}
+	if cb.Type().IsVariadic() {
+		panic("callback can't be variadic function")
+	}
+	if settings != nil && len(settings.ResultTypes) > 0 {
+		checkCallbackResultTypes(cb.Type(), settings.ResultTypes)
+	}
+	if id == "" {
+		if id, err = client.getID(); err != nil {
+			return err
+		}
+	}
+	client.topicLocker.Lock()
+	topics := client.topics[topic]
+	if topics == nil {
+		topics = make(map[string]*clientTopic)
+		client.topics[topic] = topics
+	}
+	if t := topics[id]; t != nil {
+		t.addCallback(cb)
+	} else {
+		ctx, cancel := context.WithCancel(context.Background())
+		t = newClientTopic(cancel)
+		t.addCallback(cb)
+		topics[id] = t
+		go client.subscribe(ctx, topic, id, t, getSubscribeSettings(settings))
+	}
+	client.topicLocker.Unlock()
+	return nil
+}
+
+// Unsubscribe the push topic with the specified client ids, if no id is
+// specified, all the subscriptions of the topic are cancelled.
+func (client *BaseClient) Unsubscribe(topic string, id ...string) {
+	client.topicLocker.Lock()
+	if topics := client.topics[topic]; topics != nil {
+		if len(id) == 0 {
+			for _, t := range topics {
+				t.stop()
+			}
+			delete(client.topics, topic)
+		} else {
+			for _, i := range id {
+				if t := topics[i]; t != nil {
+					t.stop()
+					delete(topics, i)
+				}
+			}
+			if len(topics) == 0 {
+				delete(client.topics, topic)
+			}
+		}
+	}
+	client.topicLocker.Unlock()
+}
+
+// IsSubscribed returns true if the topic is subscribed.
+func (client *BaseClient) IsSubscribed(topic string) bool {
+	client.topicLocker.RLock()
+	_, ok := client.topics[topic]
+	client.topicLocker.RUnlock()
+	return ok
+}
+
+// SubscribedList returns the subscribed topic list
+func (client *BaseClient) SubscribedList() []string {
+	client.topicLocker.RLock()
+	list := make([]string, 0, len(client.topics))
+	for topic := range client.topics {
+		list = append(list, topic)
+	}
+	client.topicLocker.RUnlock()
+	return list
+}
+
+func getSubscribeSettings(settings *InvokeSettings) *InvokeSettings {
+	s := new(InvokeSettings)
+	if settings != nil {
+		*s = *settings
+	}
+	s.ByRef = false
+	s.Oneway = false
+	s.Mode = Serialized
+	if s.Timeout <= 0 {
+		s.Timeout = subscribeTimeout
+	}
+	return s
+}
+
+func (client *BaseClient) subscribe(
+	ctx context.Context, topic string, id string,
+	t *clientTopic, settings *InvokeSettings) {
+	args := []reflect.Value{reflect.ValueOf(id)}
+	retried := 0
+	for !t.isStopped() {
+		results, err := client.InvokeContext(ctx, topic, args, settings)
+		if t.isStopped() || isClosedError(err) {
+			return
+		}
+		if err == ErrTimeout {
+			continue
+		}
+		if err != nil {
+			if event, ok := client.event.(onErrorEvent); ok {
+				event.OnError(topic, err)
+			}
+			for _, callback := range t.getCallbacks() {
+				client.fireTopicCallback(topic, callback, nil, err, settings)
+			}
+			retried++
+			interval := retried * 500
+			if interval > 5000 {
+				interval = 5000
+			}
+			select {
+			case <-time.After(time.Duration(interval) * time.Millisecond):
+			case <-ctx.Done():
+			}
+			continue
+		}
+		retried = 0
+		data := results[0].Bytes()
+		if len(data) == 0 || data[0] == hio.TagNull {
+			continue
+		}
+		for _, callback := range t.getCallbacks() {
+			client.fireTopicCallback(topic, callback, data, nil, settings)
+		}
+	}
+}
+
+func (client *BaseClient) fireTopicCallback(
+	topic string, callback reflect.Value,
+	data []byte, err error, settings *InvokeSettings) {
+	defer fireClientErrorEvent(client, topic, nil)
+	resultTypes, hasError := getCallbackResultTypes(callback.Type())
+	if len(settings.ResultTypes) > 0 {
+		resultTypes = settings.ResultTypes
+	}
+	if err != nil && !hasError {
+		return
+	}
+	n := len(resultTypes)
+	var in []reflect.Value
+	if err == nil {
+		reader := hio.NewReader(data, false)
+		reader.JSONCompatible = settings.JSONCompatible
+		context := new(ClientContext)
+		context.ResultTypes = resultTypes
+		if in = client.readResults(reader, context); len(in) > n {
+			in = in[:n]
+		}
+	} else {
+		in = make([]reflect.Value, n)
+		for i := 0; i < n; i++ {
+			in[i] = reflect.New(resultTypes[i]).Elem()
+		}
+	}
+	if hasError {
+		in = append(in, reflect.ValueOf(&err).Elem())
+	}
+	callback.Call(in)
+}
 
 func (client *BaseClient) beforeFilter(
 	request []byte,
 	context *ClientContext) (response []byte, err error) {
 	request = client.outputFilter(request, context)
 	if context.Oneway {
-		go client.handlerManager.afterFilterHandler(request, context)
+		// context is released to the pool when the invocation returns,
+		// so the request is sent with a copy of it.
+		oneway := new(ClientContext)
+		*oneway = *context
+		oneway.batch = nil
+		go client.handlerManager.afterFilterHandler(request, oneway)
 		return nil, nil
 	}
 	response, err = client.handlerManager.afterFilterHandler(request, context)
@@ -296,55 +1106,321 @@ func (client *BaseClient) beforeFilter(
 
 func (client *BaseClient) afterFilter(
 	request []byte, context Context) (response []byte, err error) {
-	return client.SendAndReceive(request, context.(*ClientContext))
+	clientContext := context.(*ClientContext)
+	if clientContext.uri == "" {
+		clientContext.uri = client.URI()
+	}
+	return client.SendAndReceive(request, clientContext)
+}
+
+// selectURI returns the service address for the request, selected is true
+// if the address is returned by the balancer, and only then the balancer is
+// notified when the request is done.
+func (client *BaseClient) selectURI(
+	context *ClientContext,
+	balancer Balancer,
+	breaker *CircuitBreaker) (uri string, selected bool, err error) {
+	if balancer != nil {
+		for i := len(client.URIList()); i > 0; i-- {
+			uri = balancer.Select(context)
+			if uri == "" {
+				break
+			}
+			if breaker == nil || breaker.allow(uri) {
+				return uri, true, nil
+			}
+			balancer.Done(uri, ErrCircuitOpen)
+		}
+		if breaker != nil {
+			return "", false, ErrCircuitOpen
+		}
+	}
+	uri = client.URI()
+	if breaker != nil && !breaker.allow(uri) {
+		return "", false, ErrCircuitOpen
+	}
+	return uri, false, nil
 }
 
 func (client *BaseClient) sendRequest(
 	request []byte,
 	context *ClientContext) (response []byte, err error) {
-	response, err = client.handlerManager.beforeFilterHandler(request, context)
+	if err = context.ctx.Err(); err != nil {
+		return nil, err
+	}
+	if budget := client.retryBudget; budget != nil {
+		budget.deposit()
+	}
+	for {
+		if err = client.rateLimit(context); err != nil {
+			return nil, err
+		}
+		response, err = client.trySendRequest(request, context)
+		retryErr := err
+		if err == nil {
+			if retryErr = client.serverError(response, context); retryErr == nil {
+				return
+			}
+		} else if context.Failswitch && context.ctx.Err() == nil {
+			client.failswitch()
+		}
+		delay, retry := client.retryDelay(retryErr, context)
+		if !retry {
+			return
+		}
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-context.ctx.Done():
+				timer.Stop()
+				return nil, context.ctx.Err()
+			}
+		}
+	}
+}
+
+func (client *BaseClient) trySendRequest(
+	request []byte,
+	context *ClientContext) ([]byte, error) {
+	balancer, breaker := client.balancer, client.breaker
+	uri, selected, err := client.selectURI(context, balancer, breaker)
 	if err != nil {
-		response, err = client.retrySendReqeust(request, err, context)
+		return nil, err
+	}
+	if context.Hedge > 0 && context.Idempotent && !context.Oneway &&
+		len(client.URIList()) > 1 {
+		return client.hedgeSendRequest(
+			request, context, uri, selected, balancer, breaker)
+	}
+	if !selected {
+		balancer = nil
+	}
+	return client.sendRequestTo(request, context, uri, balancer, breaker)
+}
+
+func (client *BaseClient) sendRequestTo(
+	request []byte,
+	context *ClientContext,
+	uri string,
+	balancer Balancer,
+	breaker *CircuitBreaker) (response []byte, err error) {
+	context.uri = uri
+	start := time.Now()
+	response, err = client.handlerManager.beforeFilterHandler(request, context)
+	if balancer != nil {
+		balancer.Done(uri, err)
+	}
+	if breaker != nil {
+		if context.ctx.Err() == nil {
+			breaker.done(uri, err, time.Since(start))
+		} else {
+			breaker.cancel(uri)
+		}
+	}
+	if metrics := client.metrics; metrics != nil {
+		metrics.Transferred(
+			context.method, uri, len(request), len(response), err)
 	}
 	return
 }
 
-func (client *BaseClient) retrySendReqeust(
+type hedgeResult struct {
+	context  *ClientContext
+	response []byte
+	err      error
+}
+
+// copyContext returns a copy of context for the hedged request,
+// the returned cancel func aborts the request.
+func copyContext(context *ClientContext) (*ClientContext, func()) {
+	c := new(ClientContext)
+	*c = *context
+	c.userData = make(map[string]interface{}, len(context.userData))
+	for key, value := range context.userData {
+		c.userData[key] = value
+	}
+	var cancel func()
+	c.ctx, cancel = withTimeout(context.ctx, 0)
+	return c, cancel
+}
+
+// hedgeSendRequest sends the request to uri, and sends a duplicate request to
+// another service address if there is no response after context.Hedge. The
+// first successful response is returned, and the other request is cancelled.
+// selected reports whether uri is returned by the balancer.
+func (client *BaseClient) hedgeSendRequest(
 	request []byte,
-	err error,
-	context *ClientContext) ([]byte, error) {
-	if context.Failswitch {
-		client.failswitch()
+	context *ClientContext,
+	uri string,
+	selected bool,
+	balancer Balancer,
+	breaker *CircuitBreaker) ([]byte, error) {
+	results := make(chan hedgeResult, 2)
+	send := func(c *ClientContext, uri string, balancer Balancer) {
+		response, err := client.sendRequestTo(request, c, uri, balancer, breaker)
+		results <- hedgeResult{c, response, err}
 	}
-	if context.Idempotent && context.Retried < context.Retry {
-		context.Retried++
-		interval := context.Retried * 500
+	c, cancel := copyContext(context)
+	defer cancel()
+	primary := balancer
+	if !selected {
+		primary = nil
+	}
+	go send(c, uri, primary)
+	pending := 1
+	timer := time.NewTimer(context.Hedge)
+	var result hedgeResult
+	select {
+	case result = <-results:
+		timer.Stop()
+		pending = 0
+	case <-timer.C:
+		c, cancel := copyContext(context)
+		defer cancel()
+		c.Retried++
+		if uri, ok := client.selectHedgeURI(c, uri, balancer, breaker); ok {
+			go send(c, uri, balancer)
+			pending++
+		}
+	}
+	for pending > 0 {
+		result = <-results
+		pending--
+		if result.err == nil {
+			break
+		}
+	}
+	context.uri = result.context.uri
+	context.userData = result.context.userData
+	return result.response, result.err
+}
+
+// selectHedgeURI returns a service address which is different from uri
+func (client *BaseClient) selectHedgeURI(
+	context *ClientContext,
+	uri string,
+	balancer Balancer,
+	breaker *CircuitBreaker) (string, bool) {
+	uriList := client.URIList()
+	n := len(uriList)
+	if balancer != nil {
+		for i := n; i > 0; i-- {
+			u := balancer.Select(context)
+			if u == "" {
+				break
+			}
+			if u == uri {
+				balancer.Done(u, nil)
+				continue
+			}
+			if breaker == nil || breaker.allow(u) {
+				return u, true
+			}
+			balancer.Done(u, ErrCircuitOpen)
+		}
+		return "", false
+	}
+	index := 0
+	for i, u := range uriList {
+		if u == uri {
+			index = i
+			break
+		}
+	}
+	checker := client.healthChecker
+	for i := 1; i < n; i++ {
+		u := uriList[(index+i)%n]
+		if u == uri || checker != nil && !checker.Healthy(u) {
+			continue
+		}
+		if breaker == nil || breaker.allow(u) {
+			return u, true
+		}
+	}
+	return "", false
+}
+
+func (client *BaseClient) getRetryPolicy(context *ClientContext) RetryPolicy {
+	if context.RetryPolicy != nil {
+		return context.RetryPolicy
+	}
+	return client.retryPolicy
+}
+
+// serverError returns the error in the response if the request can be retried
+// by the retry policy.
+func (client *BaseClient) serverError(
+	response []byte, context *ClientContext) (err error) {
+	if context.batched || context.Oneway || client.getRetryPolicy(context) == nil ||
+		len(response) == 0 || response[0] != hio.TagError {
+		return nil
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			err = nil
+		}
+	}()
+	reader := hio.NewReader(response[1:], false)
+	return &ServerError{reader.ReadString()}
+}
+
+func (client *BaseClient) retryDelay(
+	err error, context *ClientContext) (delay time.Duration, retry bool) {
+	if context.ctx.Err() != nil {
+		return 0, false
+	}
+	if policy := client.getRetryPolicy(context); policy != nil {
+		delay, retry = policy.Retry(context, err)
+	} else if context.Idempotent && context.Retried < context.Retry {
+		retry = true
+		interval := (context.Retried + 1) * 500
 		if context.Failswitch {
-			interval -= (len(client.uriList) - 1) * 500
+			interval -= (len(client.URIList()) - 1) * 500
 		}
 		if interval > 5000 {
 			interval = 5000
 		}
 		if interval > 0 {
-			time.Sleep(time.Duration(interval) * time.Millisecond)
+			delay = time.Duration(interval) * time.Millisecond
 		}
-		return client.sendRequest(request, context)
 	}
-	return nil, err
+	if retry {
+		if budget := client.retryBudget; budget != nil && !budget.withdraw() {
+			return 0, false
+		}
+		context.Retried++
+		if metrics := client.metrics; metrics != nil {
+			metrics.Retried(context.method, context.uri)
+		}
+	}
+	return
 }
 
 func (client *BaseClient) failswitch() {
-	n := int32(len(client.uriList))
+	client.uriLocker.Lock()
+	from := client.uri
+	n := len(client.uriList)
 	if n > 1 {
-		if atomic.CompareAndSwapInt32(&client.index, n-1, 0) {
-			client.uri = client.uriList[0]
-			client.failround++
-		} else {
-			client.uri = client.uriList[atomic.AddInt32(&client.index, 1)]
+		// skips the service addresses which are unavailable.
+		for i := n; i > 0; i-- {
+			if client.index++; client.index >= n {
+				client.index = 0
+				client.failround++
+			}
+			client.uri = client.uriList[client.index]
+			if client.available(client.uri) {
+				break
+			}
 		}
 	} else {
 		client.failround++
 	}
+	to := client.uri
+	client.uriLocker.Unlock()
+	if metrics := client.metrics; metrics != nil {
+		metrics.Failswitched(from, to)
+	}
 	if event, ok := client.event.(onFailswitchEvent); ok {
 		event.OnFailswitch(client)
 	}
@@ -459,7 +1535,7 @@ func (client *BaseClient) decode(
 			tag, _ = reader.ReadByte()
 		}
 	} else if tag == hio.TagError {
-		return nil, errors.New(reader.ReadString())
+		return nil, &ServerError{reader.ReadString()}
 	}
 	if tag != hio.TagEnd {
 		return nil, fmt.Errorf("Wrong Response: \r\n%s", data)
@@ -471,14 +1547,48 @@ func (client *BaseClient) invoke(
 	name string,
 	args []reflect.Value,
 	context *ClientContext) (results []reflect.Value, err error) {
+	context.method = name
 	request := client.encode(name, args, context)
-	response, err := client.sendRequest(request, context)
+	cache := client.getResponseCache(context)
+	if cache != nil {
+		if response, ok := cache.get(request); ok {
+			return client.decode(response, args, context)
+		}
+	}
+	var response []byte
+	if context.Coalesce && !context.Oneway {
+		response, err = client.flights.do(request, context, func() ([]byte, error) {
+			return client.roundTrip(request, context)
+		})
+	} else {
+		response, err = client.roundTrip(request, context)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if cache != nil && len(response) > 0 && response[0] != hio.TagError {
+		cache.set(name, request, response, context.Cache)
+	}
 	return client.decode(response, args, context)
 }
 
+func (client *BaseClient) roundTrip(
+	request []byte, context *ClientContext) ([]byte, error) {
+	if b := context.batch; b != nil && !context.Oneway {
+		context.batch = nil
+		return b.join(request, context)
+	}
+	return client.sendRequest(request, context)
+}
+
+func (client *BaseClient) getResponseCache(
+	context *ClientContext) *ResponseCache {
+	if context.Cache <= 0 || context.Oneway {
+		return nil
+	}
+	return client.cache
+}
+
 func buildRemoteService(client *BaseClient, v reflect.Value, ns string) {
 	v = v.Elem()
 	t := v.Type()
@@ -531,6 +1641,44 @@ func buildRemoteSubService(client *BaseClient, f reflect.Value, ft reflect.Type,
 	}
 }
 
+// getRemoteMethodFields returns the stub field paths of t paired with their
+// remote method names, the same way buildRemoteService resolves them.
+func getRemoteMethodFields(t reflect.Type, ns string, path string) (fields [][2]string) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	count := t.NumField()
+	for i := 0; i < count; i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		name := sf.Name
+		if path != "" {
+			name = path + "." + name
+		}
+		switch ft.Kind() {
+		case reflect.Struct:
+			namespace := ns
+			if !sf.Anonymous {
+				if ns == "" {
+					namespace = sf.Name
+				} else {
+					namespace += "_" + sf.Name
+				}
+			}
+			fields = append(fields, getRemoteMethodFields(ft, namespace, name)...)
+		case reflect.Func:
+			fields = append(fields, [2]string{name, getRemoteMethodName(sf, ns)})
+		}
+	}
+	return
+}
+
 func getRemoteMethodName(sf reflect.StructField, ns string) (name string) {
 	name = sf.Tag.Get("name")
 	if name == "" {
@@ -575,6 +1723,18 @@ func getInt64Value(tag reflect.StructTag, key string) int64 {
 	return result
 }
 
+func getDurationValue(tag reflect.StructTag, key string) time.Duration {
+	value := tag.Get(key)
+	if value == "" {
+		return 0
+	}
+	if result, err := time.ParseDuration(value); err == nil {
+		return result
+	}
+	result, _ := strconv.ParseInt(value, 10, 64)
+	return time.Duration(result)
+}
+
 func getResultTypes(ft reflect.Type) ([]reflect.Type, bool) {
 	n := ft.NumOut()
 	if n == 0 {
@@ -591,6 +1751,33 @@ func getResultTypes(ft reflect.Type) ([]reflect.Type, bool) {
 	return results, hasError
 }
 
+// isChanResults reports whether all the results of ft are receive-only channels
+func isChanResults(ft reflect.Type) bool {
+	n := ft.NumOut()
+	if n == 0 {
+		return false
+	}
+	for i := 0; i < n; i++ {
+		if out := ft.Out(i); out.Kind() != reflect.Chan || out.ChanDir() != reflect.RecvDir {
+			return false
+		}
+	}
+	return true
+}
+
+func getChanResultTypes(ft reflect.Type) ([]reflect.Type, bool) {
+	n := ft.NumOut()
+	hasError := (ft.Out(n-1).Elem() == errorType)
+	if hasError {
+		n--
+	}
+	results := make([]reflect.Type, n)
+	for i := 0; i < n; i++ {
+		results[i] = ft.Out(i).Elem()
+	}
+	return results, hasError
+}
+
 func getCallbackResultTypes(ft reflect.Type) ([]reflect.Type, bool) {
 	n := ft.NumIn()
 	if n == 0 {
@@ -607,6 +1794,22 @@ func getCallbackResultTypes(ft reflect.Type) ([]reflect.Type, bool) {
 	return results, hasError
 }
 
+func checkCallbackResultTypes(ft reflect.Type, resultTypes []reflect.Type) {
+	types, _ := getCallbackResultTypes(ft)
+	if len(types) != len(resultTypes) {
+		panic(fmt.Sprintf(
+			"Subscribe: callback has %d result parameters, but %d ResultTypes",
+			len(types), len(resultTypes)))
+	}
+	for i, t := range resultTypes {
+		if !t.AssignableTo(types[i]) {
+			panic(fmt.Sprintf(
+				"Subscribe: ResultTypes[%d] %v is not assignable to %v",
+				i, t, types[i]))
+		}
+	}
+}
+
 func getIn(in []reflect.Value) []reflect.Value {
 	inlen := len(in)
 	varlen := 0
@@ -626,17 +1829,30 @@ func getIn(in []reflect.Value) []reflect.Value {
 	return args
 }
 
+func getContext(
+	in []reflect.Value, hasContext bool) (context.Context, []reflect.Value) {
+	if hasContext {
+		if ctx, ok := in[0].Interface().(context.Context); ok && ctx != nil {
+			return ctx, in[1:]
+		}
+		return context.Background(), in[1:]
+	}
+	return context.Background(), in
+}
+
 func getSyncRemoteMethod(
 	client *BaseClient,
 	name string,
 	settings *InvokeSettings,
-	isVariadic, hasError bool) func(in []reflect.Value) (out []reflect.Value) {
+	isVariadic, hasError, hasContext bool) func(in []reflect.Value) (out []reflect.Value) {
 	return func(in []reflect.Value) (out []reflect.Value) {
 		if isVariadic {
 			in = getIn(in)
 		}
+		var ctx context.Context
+		ctx, in = getContext(in, hasContext)
 		var err error
-		out, err = client.Invoke(name, in, settings)
+		out, err = client.InvokeContext(ctx, name, in, settings)
 		if hasError {
 			out = append(out, reflect.ValueOf(&err).Elem())
 		} else if err != nil {
@@ -665,15 +1881,18 @@ func getAsyncRemoteMethod(
 	client *BaseClient,
 	name string,
 	settings *InvokeSettings,
-	isVariadic, hasError bool) func(in []reflect.Value) (out []reflect.Value) {
+	isVariadic, hasError, hasContext bool) func(in []reflect.Value) (out []reflect.Value) {
 	return func(in []reflect.Value) (out []reflect.Value) {
+		b := client.acquireBatch()
 		go func() {
 			if isVariadic {
 				in = getIn(in)
 			}
+			var ctx context.Context
+			ctx, in = getContext(in, hasContext)
 			callback := in[0]
 			in = in[1:]
-			out, err := client.Invoke(name, in, settings)
+			out, err := client.invokeContext(ctx, b, name, in, settings)
 			if hasError {
 				out = append(out, reflect.ValueOf(&err).Elem())
 			}
@@ -684,19 +1903,70 @@ func getAsyncRemoteMethod(
 	}
 }
 
+func getChanRemoteMethod(
+	client *BaseClient,
+	name string,
+	settings *InvokeSettings,
+	ft reflect.Type,
+	isVariadic, hasError, hasContext bool) func(in []reflect.Value) (out []reflect.Value) {
+	return func(in []reflect.Value) (out []reflect.Value) {
+		n := ft.NumOut()
+		out = make([]reflect.Value, n)
+		chans := make([]reflect.Value, n)
+		for i := 0; i < n; i++ {
+			chanType := reflect.ChanOf(reflect.BothDir, ft.Out(i).Elem())
+			chans[i] = reflect.MakeChan(chanType, 1)
+			out[i] = chans[i].Convert(ft.Out(i))
+		}
+		b := client.acquireBatch()
+		go func() {
+			if isVariadic {
+				in = getIn(in)
+			}
+			var ctx context.Context
+			ctx, in = getContext(in, hasContext)
+			results, err := client.invokeContext(ctx, b, name, in, settings)
+			if hasError {
+				n--
+				chans[n].Send(reflect.ValueOf(&err).Elem())
+				chans[n].Close()
+			} else {
+				defer fireClientErrorEvent(client, name, err)
+			}
+			for i := 0; i < n; i++ {
+				if err == nil && i < len(results) {
+					chans[i].Send(results[i])
+				} else {
+					chans[i].Send(reflect.Zero(chans[i].Type().Elem()))
+				}
+				chans[i].Close()
+			}
+		}()
+		return
+	}
+}
+
 func buildRemoteMethod(client *BaseClient, f reflect.Value, ft reflect.Type, sf reflect.StructField, ns string) {
 	name := getRemoteMethodName(sf, ns)
 	outTypes, hasError := getResultTypes(ft)
-	async := false
+	hasContext := ft.NumIn() > 0 && ft.In(0) == goContextType
+	first := 0
+	if hasContext {
+		first = 1
+	}
+	async, channel := false, false
 	if outTypes == nil && hasError == false {
-		if ft.NumIn() > 0 && ft.In(0).Kind() == reflect.Func {
-			cbft := ft.In(0)
+		if ft.NumIn() > first && ft.In(first).Kind() == reflect.Func {
+			cbft := ft.In(first)
 			if cbft.IsVariadic() {
 				panic("callback can't be variadic function")
 			}
 			outTypes, hasError = getCallbackResultTypes(cbft)
 			async = true
 		}
+	} else if isChanResults(ft) {
+		outTypes, hasError = getChanResultTypes(ft)
+		channel = true
 	}
 	settings := &InvokeSettings{
 		ByRef:          getBoolValue(sf.Tag, "byref"),
@@ -708,13 +1978,18 @@ func buildRemoteMethod(client *BaseClient, f reflect.Value, ft reflect.Type, sf
 		Retry:          int(getInt64Value(sf.Tag, "retry")),
 		Mode:           getResultMode(sf.Tag),
 		Timeout:        time.Duration(getInt64Value(sf.Tag, "timeout")),
+		Hedge:          getDurationValue(sf.Tag, "hedge"),
+		Cache:          getDurationValue(sf.Tag, "cache"),
+		Coalesce:       getBoolValue(sf.Tag, "coalesce"),
 		ResultTypes:    outTypes,
 	}
 	var fn func(in []reflect.Value) (out []reflect.Value)
 	if async {
-		fn = getAsyncRemoteMethod(client, name, settings, ft.IsVariadic(), hasError)
+		fn = getAsyncRemoteMethod(client, name, settings, ft.IsVariadic(), hasError, hasContext)
+	} else if channel {
+		fn = getChanRemoteMethod(client, name, settings, ft, ft.IsVariadic(), hasError, hasContext)
 	} else {
-		fn = getSyncRemoteMethod(client, name, settings, ft.IsVariadic(), hasError)
+		fn = getSyncRemoteMethod(client, name, settings, ft.IsVariadic(), hasError, hasContext)
 	}
 	if f.Kind() == reflect.Ptr {
 		fp := reflect.New(ft)
@@ -0,0 +1,567 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/base_client_test.go                                *
+ *                                                        *
+ * hprose base client test for Go.                        *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSubscribeResultTypes(t *testing.T) {
+	server := NewTCPServer("")
+	server.Publish("scores", 0, 0)
+	server.Handle()
+	defer server.Close()
+	client := NewTCPClient(server.URI())
+	defer client.Close()
+	scoresType := reflect.TypeOf(map[string]int(nil))
+	typed := make(chan interface{}, 1)
+	settings := &InvokeSettings{ResultTypes: []reflect.Type{scoresType}}
+	if err := client.Subscribe("scores", "", func(v interface{}) {
+		typed <- v
+	}, settings); err != nil {
+		t.Fatal(err)
+	}
+	untyped := make(chan map[string]int, 1)
+	if err := client.Subscribe("scores", "fallback", func(v map[string]int) {
+		untyped <- v
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Unsubscribe("scores")
+	id, _ := client.getID()
+	for !server.Exist("scores", id) || !server.Exist("scores", "fallback") {
+		time.Sleep(10 * time.Millisecond)
+	}
+	server.Push("scores", map[string]int{"a": 1})
+	want := map[string]int{"a": 1}
+	select {
+	case v := <-typed:
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("ResultTypes is ignored: %#v", v)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the message is not received")
+	}
+	select {
+	case v := <-untyped:
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("the callback signature is ignored: %#v", v)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the message is not received")
+	}
+}
+
+func TestSubscribeResultTypesMismatch(t *testing.T) {
+	intType := reflect.TypeOf(0)
+	readerType := reflect.TypeOf((*io.Reader)(nil)).Elem()
+	bufferType := reflect.TypeOf((*bytes.Buffer)(nil))
+	cases := []struct {
+		name        string
+		callback    interface{}
+		resultTypes []reflect.Type
+		panics      bool
+	}{
+		{"same", func(int) {}, []reflect.Type{intType}, false},
+		{"assignable", func(io.Reader, error) {},
+			[]reflect.Type{bufferType}, false},
+		{"interface", func(interface{}) {}, []reflect.Type{intType}, false},
+		{"not assignable", func(string) {}, []reflect.Type{intType}, true},
+		{"not implemented", func(io.Reader) {},
+			[]reflect.Type{intType}, true},
+		{"interface to concrete", func(*bytes.Buffer) {},
+			[]reflect.Type{readerType}, true},
+		{"too many", func(int) {}, []reflect.Type{intType, intType}, true},
+		{"too few", func(int, int, error) {}, []reflect.Type{intType}, true},
+	}
+	client := NewTCPClient("tcp://127.0.0.1:0")
+	defer client.Close()
+	for _, c := range cases {
+		func() {
+			defer func() {
+				if e := recover(); (e != nil) != c.panics {
+					t.Errorf("%s: panic %v, want %v", c.name, e, c.panics)
+				}
+			}()
+			settings := &InvokeSettings{ResultTypes: c.resultTypes}
+			client.Subscribe("topic", "id", c.callback, settings)
+			client.Unsubscribe("topic")
+		}()
+	}
+}
+
+func TestOnewayInvoke(t *testing.T) {
+	server := NewTCPServer("")
+	received := make(chan string, 10)
+	server.AddFunction("log", func(message string) {
+		received <- message
+	}, Options{})
+	server.Handle()
+	defer server.Close()
+	client := NewTCPClient(server.URI())
+	defer client.Close()
+	client.AddAfterFilterHandler(func(
+		request []byte, context Context,
+		next NextFilterHandler) (response []byte, err error) {
+		time.Sleep(50 * time.Millisecond)
+		return next(request, context)
+	})
+	settings := &InvokeSettings{Oneway: true}
+	for i := 0; i < 3; i++ {
+		args := []reflect.Value{reflect.ValueOf("message")}
+		if _, err := client.Invoke("log", args, settings); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case <-received:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%d oneway calls are received, want 3", i)
+		}
+	}
+}
+
+type scriptedBalancer struct {
+	sync.Mutex
+	uri         string
+	outstanding map[string]int
+	selected    int
+}
+
+func (balancer *scriptedBalancer) SetURIList(uriList []string) {}
+
+func (balancer *scriptedBalancer) Select(context *ClientContext) string {
+	balancer.Lock()
+	defer balancer.Unlock()
+	if balancer.uri != "" {
+		balancer.outstanding[balancer.uri]++
+		balancer.selected++
+	}
+	return balancer.uri
+}
+
+func (balancer *scriptedBalancer) Done(uri string, err error) {
+	balancer.Lock()
+	balancer.outstanding[uri]--
+	balancer.Unlock()
+}
+
+// pending returns a uri whose Done calls do not match its Select calls.
+func (balancer *scriptedBalancer) pending() string {
+	balancer.Lock()
+	defer balancer.Unlock()
+	for uri, n := range balancer.outstanding {
+		if n != 0 {
+			return uri
+		}
+	}
+	return ""
+}
+
+func TestBalancerDone(t *testing.T) {
+	servers := make([]*TCPServer, 2)
+	uris := make([]string, 2)
+	for i := range servers {
+		servers[i] = NewTCPServer("")
+		servers[i].AddFunction("hello", func(name string) string {
+			time.Sleep(20 * time.Millisecond)
+			return "hello " + name
+		}, Options{})
+		servers[i].Handle()
+		defer servers[i].Close()
+		uris[i] = servers[i].URI()
+	}
+	cases := []struct {
+		name     string
+		uri      string
+		hedge    time.Duration
+		selected bool
+	}{
+		{"fallback", "", 0, false},
+		{"fallback with hedge", "", 5 * time.Millisecond, false},
+		{"selected", uris[0], 0, true},
+		{"selected with hedge", uris[0], 5 * time.Millisecond, true},
+	}
+	for _, c := range cases {
+		client := NewTCPClient(uris...)
+		balancer := &scriptedBalancer{
+			uri: c.uri, outstanding: make(map[string]int)}
+		client.SetBalancer(balancer)
+		settings := &InvokeSettings{Hedge: c.hedge, Idempotent: true}
+		args := []reflect.Value{reflect.ValueOf("world")}
+		if _, err := client.Invoke("hello", args, settings); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+		}
+		// the cancelled hedged request is finished after Invoke returns.
+		for i := 0; i < 100 && balancer.pending() != ""; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		client.Close()
+		if uri := balancer.pending(); uri != "" {
+			t.Errorf("%s: %d outstanding requests of %s",
+				c.name, balancer.outstanding[uri], uri)
+		}
+		balancer.Lock()
+		if (balancer.selected > 0) != c.selected {
+			t.Errorf("%s: %d uris are selected", c.name, balancer.selected)
+		}
+		balancer.Unlock()
+	}
+}
+
+type hedgeServer struct {
+	*TCPServer
+	name  string
+	delay int64
+	calls int32
+}
+
+func newHedgeServer(name string) *hedgeServer {
+	server := &hedgeServer{TCPServer: NewTCPServer(""), name: name}
+	server.AddFunction("who", func() string {
+		atomic.AddInt32(&server.calls, 1)
+		time.Sleep(time.Duration(atomic.LoadInt64(&server.delay)))
+		return name
+	}, Options{})
+	server.Handle()
+	return server
+}
+
+func TestHedgeSendRequest(t *testing.T) {
+	servers := []*hedgeServer{newHedgeServer("a"), newHedgeServer("b")}
+	uris := make([]string, len(servers))
+	for i, server := range servers {
+		defer server.Close()
+		uris[i] = server.URI()
+	}
+	const hedge = 20 * time.Millisecond
+	// delays, want and calls are indexed by the primary address first.
+	cases := []struct {
+		name       string
+		delays     [2]time.Duration
+		idempotent bool
+		uris       int
+		want       int
+		calls      [2]int32
+	}{
+		{"primary responds in time", [2]time.Duration{0, 0}, true, 2,
+			0, [2]int32{1, 0}},
+		{"hedged request responds first",
+			[2]time.Duration{300 * time.Millisecond, 0}, true, 2,
+			1, [2]int32{1, 1}},
+		{"primary responds first",
+			[2]time.Duration{60 * time.Millisecond, 300 * time.Millisecond},
+			true, 2, 0, [2]int32{1, 1}},
+		{"not idempotent", [2]time.Duration{60 * time.Millisecond, 0},
+			false, 2, 0, [2]int32{1, 0}},
+		{"single address", [2]time.Duration{60 * time.Millisecond, 0},
+			true, 1, 0, [2]int32{1, 0}},
+	}
+	for _, c := range cases {
+		client := NewTCPClient(uris[:c.uris]...)
+		ordered := servers
+		if client.URI() == servers[1].URI() {
+			ordered = []*hedgeServer{servers[1], servers[0]}
+		}
+		for i, server := range ordered {
+			atomic.StoreInt64(&server.delay, int64(c.delays[i]))
+			atomic.StoreInt32(&server.calls, 0)
+		}
+		settings := &InvokeSettings{
+			Hedge:       hedge,
+			Idempotent:  c.idempotent,
+			ResultTypes: []reflect.Type{stringType},
+		}
+		start := time.Now()
+		results, err := client.Invoke("who", nil, settings)
+		elapsed := time.Since(start)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+		} else if who := results[0].String(); who != ordered[c.want].name {
+			t.Errorf("%s: responded by %s, want %s",
+				c.name, who, ordered[c.want].name)
+		}
+		if elapsed >= 300*time.Millisecond {
+			t.Errorf("%s: waits for the slow request: %v", c.name, elapsed)
+		}
+		for i, server := range ordered {
+			calls := atomic.LoadInt32(&server.calls)
+			for j := 0; j < 50 && calls < c.calls[i]; j++ {
+				time.Sleep(10 * time.Millisecond)
+				calls = atomic.LoadInt32(&server.calls)
+			}
+			if calls != c.calls[i] {
+				t.Errorf("%s: %s is called %d times, want %d",
+					c.name, server.name, calls, c.calls[i])
+			}
+		}
+		client.Close()
+	}
+}
+
+func TestSelectHedgeURI(t *testing.T) {
+	cases := []struct {
+		name      string
+		uri       string
+		unhealthy []string
+		balanced  string
+		want      string
+	}{
+		{"next address", "tcp://a", nil, "", "tcp://b"},
+		{"wraps around", "tcp://c", nil, "", "tcp://a"},
+		{"skips unhealthy", "tcp://a", []string{"tcp://b"}, "", "tcp://c"},
+		{"all unhealthy", "tcp://a",
+			[]string{"tcp://b", "tcp://c"}, "", ""},
+		{"balanced", "tcp://a", nil, "tcp://c", "tcp://c"},
+		{"balanced the same", "tcp://a", nil, "tcp://a", ""},
+	}
+	for _, c := range cases {
+		client := NewTCPClient(testURIList...)
+		client.uriList = testURIList
+		checker := NewHealthChecker()
+		checker.setURIList(testURIList)
+		for _, uri := range c.unhealthy {
+			checker.endpoints[uri].healthy = false
+		}
+		client.healthChecker = checker
+		var balancer *scriptedBalancer
+		if c.balanced != "" {
+			balancer = &scriptedBalancer{
+				uri: c.balanced, outstanding: make(map[string]int)}
+			client.balancer = balancer
+		}
+		clientContext := &ClientContext{ctx: context.Background()}
+		uri, ok := client.selectHedgeURI(
+			clientContext, c.uri, client.balancer, nil)
+		if uri != c.want || ok != (c.want != "") {
+			t.Errorf("%s: select %q %v, want %q", c.name, uri, ok, c.want)
+		}
+		if balancer != nil {
+			if uri != "" {
+				balancer.Done(uri, nil)
+			}
+			if pending := balancer.pending(); pending != "" {
+				t.Errorf("%s: %s is not done", c.name, pending)
+			}
+		}
+		client.Close()
+	}
+}
+
+type chanStub struct {
+	Sum        func(a, b int) <-chan int
+	SumError   func(a, b int) (<-chan int, <-chan error) `name:"sum"`
+	SumContext func(
+		ctx context.Context, a, b int) (<-chan int, <-chan error) `name:"sum"`
+	Total     func(a ...int) (<-chan int, <-chan error)
+	Fail      func() (<-chan int, <-chan error)
+	FailValue func() <-chan int `name:"fail"`
+}
+
+// receive returns the value and the error sent to the channels, and reports
+// whether both channels are closed after that.
+func receive(result <-chan int, err <-chan error) (int, error, bool) {
+	value := <-result
+	_, open := <-result
+	if err == nil {
+		return value, nil, !open
+	}
+	e := <-err
+	_, errOpen := <-err
+	return value, e, !open && !errOpen
+}
+
+func TestChanRemoteMethod(t *testing.T) {
+	server := NewTCPServer("")
+	server.AddFunction("sum", func(a, b int) int { return a + b }, Options{})
+	server.AddFunction("total", func(a ...int) (total int) {
+		for _, v := range a {
+			total += v
+		}
+		return
+	}, Options{})
+	server.AddFunction("fail", func() (int, error) {
+		return 0, errors.New("failed")
+	}, Options{})
+	server.Handle()
+	defer server.Close()
+	client := NewTCPClient(server.URI())
+	defer client.Close()
+	var stub *chanStub
+	client.UseService(&stub)
+	cases := []struct {
+		name string
+		call func() (int, error, bool)
+		want int
+		err  string
+	}{
+		{"value", func() (int, error, bool) {
+			return receive(stub.Sum(1, 2), nil)
+		}, 3, ""},
+		{"value and error", func() (int, error, bool) {
+			return receive(stub.SumError(1, 2))
+		}, 3, ""},
+		{"context", func() (int, error, bool) {
+			return receive(stub.SumContext(context.Background(), 1, 2))
+		}, 3, ""},
+		{"variadic", func() (int, error, bool) {
+			return receive(stub.Total(1, 2, 3))
+		}, 6, ""},
+		{"error", func() (int, error, bool) {
+			return receive(stub.Fail())
+		}, 0, "failed"},
+		{"error without error channel", func() (int, error, bool) {
+			return receive(stub.FailValue(), nil)
+		}, 0, ""},
+	}
+	for _, c := range cases {
+		value, err, closed := c.call()
+		if value != c.want {
+			t.Errorf("%s: receive %d, want %d", c.name, value, c.want)
+		}
+		if err == nil && c.err != "" || err != nil && err.Error() != c.err {
+			t.Errorf("%s: receive error %v, want %q", c.name, err, c.err)
+		}
+		if !closed {
+			t.Errorf("%s: the channels are not closed", c.name)
+		}
+	}
+}
+
+func TestDecodeFunctionList(t *testing.T) {
+	cases := []struct {
+		name  string
+		data  string
+		want  []string
+		error string
+	}{
+		{"functions", `Fa2{s5"hello"s3"sum"}z`, []string{"hello", "sum"}, ""},
+		{"server error", `Es6"failed"z`, nil, "failed"},
+		{"empty", "", nil, io.ErrUnexpectedEOF.Error()},
+		{"unterminated", `Fa1{s5"hello"}`, nil, "Wrong Response"},
+		{"unexpected tag", `Ra1{s5"hello"}z`, nil, "Wrong Response"},
+	}
+	for _, c := range cases {
+		names, err := decodeFunctionList([]byte(c.data))
+		if c.error == "" && err != nil ||
+			c.error != "" && (err == nil || !strings.Contains(err.Error(), c.error)) {
+			t.Errorf("%s: error %v, want %q", c.name, err, c.error)
+		}
+		if !reflect.DeepEqual(names, c.want) {
+			t.Errorf("%s: decode %v, want %v", c.name, names, c.want)
+		}
+	}
+}
+
+type strictStub struct {
+	Hello   func(string) (string, error)
+	Sum     func(int, int) (int, error) `name:"SUM"`
+	Missing func() error
+	Math    struct {
+		Max func(int, int) (int, error)
+		Min func(int, int) (int, error)
+	}
+}
+
+type strictCompleteStub struct {
+	Hello func(string) (string, error)
+	Math  struct {
+		Max func(int, int) (int, error)
+	}
+}
+
+func TestStrictUseService(t *testing.T) {
+	server := NewTCPServer("")
+	server.AddFunction("hello", func(name string) string {
+		return "hello " + name
+	}, Options{})
+	server.AddFunction("sum", func(a, b int) int { return a + b }, Options{})
+	server.AddFunction("Math_Max", func(a, b int) int {
+		if a > b {
+			return a
+		}
+		return b
+	}, Options{})
+	server.Handle()
+	defer server.Close()
+	missing := NewTCPServer("")
+	missing.AddMissingMethod(func(name string, args []reflect.Value,
+		context Context) []reflect.Value {
+		return nil
+	}, Options{})
+	missing.Handle()
+	defer missing.Close()
+	client := NewTCPClient(server.URI())
+	defer client.Close()
+	names, err := client.FunctionList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	published := make(map[string]bool, len(names))
+	for _, name := range names {
+		published[name] = true
+	}
+	for _, name := range []string{"hello", "sum", "Math_Max"} {
+		if !published[name] {
+			t.Errorf("FunctionList %v has no %s", names, name)
+		}
+	}
+	cases := []struct {
+		name    string
+		uri     string
+		strict  bool
+		stub    interface{}
+		missing string
+	}{
+		{"not strict", server.URI(), false, new(*strictStub), ""},
+		{"complete", server.URI(), true, new(*strictCompleteStub), ""},
+		{"incomplete", server.URI(), true, new(*strictStub),
+			"Missing (Missing), Math.Min (Math_Min)"},
+		{"missing method", missing.URI(), true, new(*strictStub), ""},
+	}
+	for _, c := range cases {
+		client := NewTCPClient(c.uri)
+		client.SetStrictService(c.strict)
+		func() {
+			defer func() {
+				e := recover()
+				if c.missing == "" && e != nil {
+					t.Errorf("%s: panic %v", c.name, e)
+				}
+				if c.missing != "" && (e == nil ||
+					!strings.HasSuffix(e.(error).Error(), ": "+c.missing)) {
+					t.Errorf("%s: panic %v, want %s", c.name, e, c.missing)
+				}
+			}()
+			client.UseService(c.stub)
+		}()
+		client.Close()
+	}
+}
@@ -12,7 +12,7 @@
  *                                                        *
  * hprose base service for Go.                            *
  *                                                        *
- * LastModified: Oct 7, 2016                              *
+ * LastModified: Oct 17, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -101,6 +101,12 @@ func (service *BaseService) AddFunction(name string, function interface{}, optio
 	return service
 }
 
+// AddInvoker publish a method which is invoked by the invoker
+func (service *BaseService) AddInvoker(name string, invoker Invoker, options Options) Service {
+	service.methodManager.AddInvoker(name, invoker, options)
+	return service
+}
+
 // AddFunctions is used for batch publishing service method
 func (service *BaseService) AddFunctions(names []string, functions []interface{}, options Options) Service {
 	service.methodManager.AddFunctions(names, functions, options)
@@ -209,6 +215,9 @@ func callService(
 	name string, args []reflect.Value,
 	context ServiceContext) (results []reflect.Value, err error) {
 	remoteMethod := context.Method()
+	if remoteMethod.Invoker != nil {
+		return remoteMethod.Invoker.Invoke(args)
+	}
 	function := remoteMethod.Function
 	if context.IsMissingMethod() {
 		missingMethod := function.Interface().(MissingMethod)
@@ -365,11 +374,18 @@ func readArguments(
 	reader *io.Reader,
 	method *Method,
 	context ServiceContext) (args []reflect.Value) {
-	reader.JSONCompatible = method.JSONCompatible
 	if method == nil {
 		return reader.ReadSliceWithoutTag()
 	}
+	reader.JSONCompatible = method.JSONCompatible
 	count := reader.ReadCount()
+	if method.Invoker != nil {
+		args = method.Invoker.ReadArguments(reader, count)
+		if len(args) > count {
+			fixArguments(args, context)
+		}
+		return
+	}
 	ft := method.Function.Type()
 	n := ft.NumIn()
 	if ft.IsVariadic() {
@@ -433,6 +449,8 @@ func mergeResult(results [][]byte) []byte {
 
 func (service *BaseService) doSingleInvoke(
 	reader *io.Reader, context ServiceContext) (result []byte, tag byte) {
+	context.setByRef(false)
+	context.setIsMissingMethod(false)
 	name := reader.ReadString()
 	alias := strings.ToLower(name)
 	method := service.RemoteMethods[alias]
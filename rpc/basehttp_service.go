@@ -12,7 +12,7 @@
  *                                                        *
  * hprose basehttp service for Go.                        *
  *                                                        *
- * LastModified: Oct 2, 2016                              *
+ * LastModified: Oct 18, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -28,9 +28,11 @@ import (
 
 type baseHTTPService struct {
 	BaseService
+	compressor
 	P3P                          bool
 	GET                          bool
 	CrossDomain                  bool
+	Compression                  bool
 	accessControlAllowOrigins    map[string]bool
 	lastModified                 string
 	etag                         string
@@ -44,9 +46,11 @@ func (service *baseHTTPService) initBaseHTTPService() {
 	t := time.Now().UTC()
 	rand.Seed(t.UnixNano())
 	service.initBaseService()
+	service.initCompressor()
 	service.P3P = true
 	service.GET = true
 	service.CrossDomain = true
+	service.Compression = true
 	service.accessControlAllowOrigins = make(map[string]bool)
 	service.lastModified = t.Format(time.RFC1123)
 	service.etag = `"` + strconv.FormatInt(rand.Int63(), 16) + `"`
@@ -109,3 +113,22 @@ func (service *baseHTTPService) SetClientAccessPolicyXMLContent(content []byte)
 	service.clientAccessPolicyXMLFile = ""
 	service.clientAccessPolicyXMLContent = content
 }
+
+// compressResponse compresses the response by the content encoding which
+// the client accepts, and returns the content encoding, the response is
+// not compressed if the encoding is empty.
+func (service *baseHTTPService) compressResponse(
+	response []byte, acceptEncoding string) ([]byte, string) {
+	if !service.Compression || !service.shouldCompress(response) {
+		return response, ""
+	}
+	encoding := preferredEncoding(acceptEncoding)
+	if encoding == "" {
+		return response, ""
+	}
+	data, err := service.compress(response, encoding)
+	if err != nil {
+		return response, ""
+	}
+	return data, encoding
+}
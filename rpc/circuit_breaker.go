@@ -0,0 +1,261 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/circuit_breaker.go                                 *
+ *                                                        *
+ * hprose circuit breaker for client.                     *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of the circuit breaker
+type BreakerState int
+
+const (
+	// BreakerClosed means the requests are sent to the service address
+	BreakerClosed BreakerState = iota
+	// BreakerOpen means the requests are not sent to the service address
+	BreakerOpen
+	// BreakerHalfOpen means the service address is on trial
+	BreakerHalfOpen
+)
+
+func (state BreakerState) String() string {
+	switch state {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+type breakerEndpoint struct {
+	state    BreakerState
+	requests int
+	failures int
+	start    time.Time
+	trial    bool
+	timer    *time.Timer
+}
+
+// CircuitBreaker opens the circuit of a service address when the error rate of
+// the requests sent to it reaches the threshold. The requests are not sent to
+// the open service address, and it becomes half-open after OpenTimeout. If
+// Probe is true, the half-open service address is probed by the built-in "#"
+// function, otherwise a request is sent to it on trial. It is closed again if
+// the probe or the trial is successful, otherwise it is open again.
+//
+// A CircuitBreaker can't be shared by clients.
+type CircuitBreaker struct {
+	// ErrorRate is the error rate threshold to open the circuit, 0.5 by default.
+	ErrorRate float64
+	// SlowCallDuration is the latency threshold, the request which takes more
+	// time than it is counted as an error, 0 means no latency threshold.
+	SlowCallDuration time.Duration
+	// MinRequests is the min number of the requests in Window before the
+	// circuit can be opened, 10 by default.
+	MinRequests int
+	// Window is the time window of the error rate statistics, 10s by default.
+	Window time.Duration
+	// OpenTimeout is the duration of the open state, 5s by default.
+	OpenTimeout time.Duration
+	// Probe indicates the half-open service address is probed by "#"
+	Probe     bool
+	locker    sync.Mutex
+	endpoints map[string]*breakerEndpoint
+	changed   func(uri string, state BreakerState)
+	probe     func(uri string) error
+}
+
+// NewCircuitBreaker is the constructor of CircuitBreaker
+func NewCircuitBreaker() *CircuitBreaker {
+	return &CircuitBreaker{
+		ErrorRate:   0.5,
+		MinRequests: 10,
+		Window:      10 * time.Second,
+		OpenTimeout: 5 * time.Second,
+		endpoints:   make(map[string]*breakerEndpoint),
+	}
+}
+
+// State returns the circuit state of the service address
+func (breaker *CircuitBreaker) State(uri string) BreakerState {
+	breaker.locker.Lock()
+	defer breaker.locker.Unlock()
+	if endpoint := breaker.endpoints[uri]; endpoint != nil {
+		return endpoint.state
+	}
+	return BreakerClosed
+}
+
+func (breaker *CircuitBreaker) setURIList(uriList []string) {
+	uris := make(map[string]bool, len(uriList))
+	for _, uri := range uriList {
+		uris[uri] = true
+	}
+	breaker.locker.Lock()
+	for uri, endpoint := range breaker.endpoints {
+		if !uris[uri] {
+			if endpoint.timer != nil {
+				endpoint.timer.Stop()
+			}
+			delete(breaker.endpoints, uri)
+		}
+	}
+	breaker.locker.Unlock()
+}
+
+// available returns true if the requests can be sent to the service address
+func (breaker *CircuitBreaker) available(uri string) bool {
+	state := breaker.State(uri)
+	return state == BreakerClosed || state == BreakerHalfOpen && !breaker.Probe
+}
+
+// allow returns true if the request can be sent to the service address now
+func (breaker *CircuitBreaker) allow(uri string) bool {
+	breaker.locker.Lock()
+	defer breaker.locker.Unlock()
+	endpoint := breaker.endpoints[uri]
+	if endpoint == nil {
+		return true
+	}
+	switch endpoint.state {
+	case BreakerClosed:
+		return true
+	case BreakerHalfOpen:
+		if !breaker.Probe && !endpoint.trial {
+			endpoint.trial = true
+			return true
+		}
+	}
+	return false
+}
+
+// done records the result of the request sent to the service address
+func (breaker *CircuitBreaker) done(uri string, err error, elapsed time.Duration) {
+	failed := err != nil ||
+		breaker.SlowCallDuration > 0 && elapsed > breaker.SlowCallDuration
+	breaker.locker.Lock()
+	endpoint := breaker.endpoints[uri]
+	if endpoint == nil {
+		endpoint = &breakerEndpoint{start: time.Now()}
+		breaker.endpoints[uri] = endpoint
+	}
+	var state BreakerState
+	switch endpoint.state {
+	case BreakerClosed:
+		if now := time.Now(); now.Sub(endpoint.start) > breaker.Window {
+			endpoint.start = now
+			endpoint.requests = 0
+			endpoint.failures = 0
+		}
+		endpoint.requests++
+		if failed {
+			endpoint.failures++
+		}
+		if !failed || endpoint.requests < breaker.MinRequests ||
+			float64(endpoint.failures) < breaker.ErrorRate*float64(endpoint.requests) {
+			breaker.locker.Unlock()
+			return
+		}
+		state = breaker.open(uri, endpoint)
+	case BreakerHalfOpen:
+		if !endpoint.trial || breaker.Probe {
+			breaker.locker.Unlock()
+			return
+		}
+		state = breaker.trialDone(uri, endpoint, failed)
+	default:
+		breaker.locker.Unlock()
+		return
+	}
+	breaker.locker.Unlock()
+	breaker.change(uri, state)
+}
+
+// cancel ends the trial of the half-open service address without result when
+// the request is cancelled, so another request can be sent to it on trial.
+func (breaker *CircuitBreaker) cancel(uri string) {
+	breaker.locker.Lock()
+	if endpoint := breaker.endpoints[uri]; endpoint != nil &&
+		endpoint.state == BreakerHalfOpen {
+		endpoint.trial = false
+	}
+	breaker.locker.Unlock()
+}
+
+// open the circuit, breaker.locker must be locked.
+func (breaker *CircuitBreaker) open(
+	uri string, endpoint *breakerEndpoint) BreakerState {
+	endpoint.state = BreakerOpen
+	endpoint.trial = false
+	endpoint.timer = time.AfterFunc(breaker.OpenTimeout, func() {
+		breaker.halfOpen(uri, endpoint)
+	})
+	return BreakerOpen
+}
+
+// trialDone closes or opens the half-open circuit by the result of the trial,
+// breaker.locker must be locked.
+func (breaker *CircuitBreaker) trialDone(
+	uri string, endpoint *breakerEndpoint, failed bool) BreakerState {
+	if failed {
+		return breaker.open(uri, endpoint)
+	}
+	endpoint.state = BreakerClosed
+	endpoint.trial = false
+	endpoint.start = time.Now()
+	endpoint.requests = 0
+	endpoint.failures = 0
+	return BreakerClosed
+}
+
+func (breaker *CircuitBreaker) halfOpen(uri string, endpoint *breakerEndpoint) {
+	breaker.locker.Lock()
+	if breaker.endpoints[uri] != endpoint || endpoint.state != BreakerOpen {
+		breaker.locker.Unlock()
+		return
+	}
+	endpoint.state = BreakerHalfOpen
+	endpoint.timer = nil
+	probe := breaker.Probe && breaker.probe != nil
+	breaker.locker.Unlock()
+	breaker.change(uri, BreakerHalfOpen)
+	if !probe {
+		return
+	}
+	err := breaker.probe(uri)
+	breaker.locker.Lock()
+	if breaker.endpoints[uri] != endpoint || endpoint.state != BreakerHalfOpen {
+		breaker.locker.Unlock()
+		return
+	}
+	state := breaker.trialDone(uri, endpoint, err != nil)
+	breaker.locker.Unlock()
+	breaker.change(uri, state)
+}
+
+func (breaker *CircuitBreaker) change(uri string, state BreakerState) {
+	if changed := breaker.changed; changed != nil {
+		changed(uri, state)
+	}
+}
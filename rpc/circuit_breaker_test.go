@@ -0,0 +1,135 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/circuit_breaker_test.go                            *
+ *                                                        *
+ * hprose circuit breaker test for Go.                    *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestBreaker() *CircuitBreaker {
+	breaker := NewCircuitBreaker()
+	breaker.MinRequests = 2
+	breaker.OpenTimeout = 50 * time.Millisecond
+	return breaker
+}
+
+func waitBreakerState(
+	t *testing.T, breaker *CircuitBreaker, uri string, state BreakerState) {
+	deadline := time.Now().Add(time.Second)
+	for breaker.State(uri) != state {
+		if time.Now().After(deadline) {
+			t.Fatalf("state is %v, want %v", breaker.State(uri), state)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCircuitBreakerCycle(t *testing.T) {
+	const uri = "tcp://127.0.0.1:1"
+	breaker := newTestBreaker()
+	var locker sync.Mutex
+	var changes []BreakerState
+	breaker.changed = func(u string, state BreakerState) {
+		locker.Lock()
+		changes = append(changes, state)
+		locker.Unlock()
+	}
+	failure := errors.New("failure")
+	breaker.done(uri, failure, 0)
+	if breaker.State(uri) != BreakerClosed {
+		t.Fatal("circuit is opened before MinRequests")
+	}
+	breaker.done(uri, failure, 0)
+	if breaker.State(uri) != BreakerOpen {
+		t.Fatal("circuit isn't opened")
+	}
+	if breaker.available(uri) || breaker.allow(uri) {
+		t.Fatal("open circuit allows requests")
+	}
+	waitBreakerState(t, breaker, uri, BreakerHalfOpen)
+	if !breaker.available(uri) || !breaker.allow(uri) {
+		t.Fatal("half-open circuit doesn't allow the trial")
+	}
+	if breaker.allow(uri) {
+		t.Fatal("half-open circuit allows two trials")
+	}
+	breaker.done(uri, nil, 0)
+	if breaker.State(uri) != BreakerClosed || !breaker.allow(uri) {
+		t.Fatal("successful trial doesn't close the circuit")
+	}
+	want := []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerClosed}
+	locker.Lock()
+	defer locker.Unlock()
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("changes are %v, want %v", changes, want)
+	}
+}
+
+func TestCircuitBreakerFailedTrial(t *testing.T) {
+	const uri = "tcp://127.0.0.1:1"
+	breaker := newTestBreaker()
+	breaker.MinRequests = 1
+	breaker.done(uri, errors.New("failure"), 0)
+	waitBreakerState(t, breaker, uri, BreakerHalfOpen)
+	breaker.allow(uri)
+	breaker.done(uri, errors.New("failure"), 0)
+	if breaker.State(uri) != BreakerOpen {
+		t.Fatal("failed trial doesn't open the circuit")
+	}
+}
+
+func TestCircuitBreakerCancelledTrial(t *testing.T) {
+	server := NewTCPServer("")
+	started := make(chan struct{}, 1)
+	server.AddFunction("wait", func() {
+		started <- struct{}{}
+		time.Sleep(200 * time.Millisecond)
+	}, Options{})
+	server.Handle()
+	defer server.Close()
+	uri := server.URI()
+	client := NewTCPClient(uri)
+	defer client.Close()
+	breaker := newTestBreaker()
+	breaker.MinRequests = 1
+	client.SetCircuitBreaker(breaker)
+	breaker.done(uri, errors.New("failure"), 0)
+	waitBreakerState(t, breaker, uri, BreakerHalfOpen)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	_, err := client.InvokeContext(ctx, "wait", nil, &InvokeSettings{})
+	if err != context.Canceled {
+		t.Fatalf("err is %v, want %v", err, context.Canceled)
+	}
+	if breaker.State(uri) != BreakerHalfOpen {
+		t.Fatalf("state is %v, want %v", breaker.State(uri), BreakerHalfOpen)
+	}
+	if !breaker.available(uri) || !breaker.allow(uri) {
+		t.Fatal("cancelled trial isn't ended")
+	}
+}
@@ -12,7 +12,7 @@
  *                                                        *
  * hprose rpc client for Go.                              *
  *                                                        *
- * LastModified: Oct 2, 2016                              *
+ * LastModified: Oct 17, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -20,8 +20,12 @@
 package rpc
 
 import (
+	"context"
 	"crypto/tls"
+	"net/url"
 	"reflect"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -37,6 +41,10 @@ type InvokeSettings struct {
 	Mode           ResultMode
 	Timeout        time.Duration
 	ResultTypes    []reflect.Type
+	RetryPolicy    RetryPolicy
+	Hedge          time.Duration
+	Cache          time.Duration
+	Coalesce       bool
 }
 
 // Callback is the callback function type of Client.Go
@@ -48,12 +56,36 @@ type Client interface {
 	SetURI(uri string)
 	URIList() []string
 	SetURIList(uriList []string)
+	Resolver() Resolver
+	SetResolver(resolver Resolver) error
+	Balancer() Balancer
+	SetBalancer(balancer Balancer)
+	CircuitBreaker() *CircuitBreaker
+	SetCircuitBreaker(breaker *CircuitBreaker)
+	HealthChecker() *HealthChecker
+	SetHealthChecker(checker *HealthChecker)
 	TLSClientConfig() *tls.Config
 	SetTLSClientConfig(config *tls.Config)
 	Retry() int
 	SetRetry(value int)
+	RetryPolicy() RetryPolicy
+	SetRetryPolicy(policy RetryPolicy)
+	RetryBudget() *RetryBudget
+	SetRetryBudget(budget *RetryBudget)
+	RateLimiter() RateLimiter
+	SetRateLimiter(limiter RateLimiter)
+	MethodRateLimiter(method string) RateLimiter
+	SetMethodRateLimiter(method string, limiter RateLimiter)
+	RateLimitFailFast() bool
+	SetRateLimitFailFast(value bool)
+	ResponseCache() *ResponseCache
+	SetResponseCache(cache *ResponseCache)
+	Metrics() MetricsCollector
+	SetMetrics(metrics MetricsCollector)
 	Timeout() time.Duration
 	SetTimeout(value time.Duration)
+	StrictService() bool
+	SetStrictService(value bool)
 	Failround() int
 	SetEvent(ClientEvent)
 	Filter() Filter
@@ -66,8 +98,17 @@ type Client interface {
 	AddBeforeFilterHandler(handler ...FilterHandler) Client
 	AddAfterFilterHandler(handler ...FilterHandler) Client
 	UseService(remoteService interface{}, namespace ...string)
+	FunctionList() ([]string, error)
 	Invoke(string, []reflect.Value, *InvokeSettings) ([]reflect.Value, error)
 	Go(string, []reflect.Value, Callback, *InvokeSettings)
+	InvokeContext(context.Context, string, []reflect.Value, *InvokeSettings) ([]reflect.Value, error)
+	GoContext(context.Context, string, []reflect.Value, Callback, *InvokeSettings)
+	BeginBatch()
+	EndBatch()
+	Subscribe(topic string, id string, callback interface{}, settings *InvokeSettings) error
+	Unsubscribe(topic string, id ...string)
+	IsSubscribed(topic string) bool
+	SubscribedList() []string
 	Close()
 }
 
@@ -77,4 +118,131 @@ type ClientContext struct {
 	InvokeSettings
 	Retried int
 	Client  Client
+	ctx     context.Context
+	batch   *batch
+	uri     string
+	batched bool
+	method  string
+}
+
+// Context returns the context.Context of the invocation
+func (context *ClientContext) Context() context.Context {
+	return context.ctx
+}
+
+// URI returns the service address which the request is sent to
+func (context *ClientContext) URI() string {
+	return context.uri
+}
+
+// withTimeout returns a copy of ctx which is cancelled after the timeout,
+// if timeout is not positive, it is only cancelled by the returned cancel.
+func withTimeout(
+	ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// timeoutError returns the error of parent if it is done,
+// otherwise returns ErrTimeout.
+func timeoutError(parent context.Context) error {
+	if err := parent.Err(); err != nil {
+		return err
+	}
+	if deadline, ok := parent.Deadline(); ok && !time.Now().Before(deadline) {
+		return context.DeadlineExceeded
+	}
+	return ErrTimeout
+}
+
+// watchContext wakes up all the waiters of cond when ctx is done,
+// the returned stop func must be called after waiting.
+func watchContext(ctx context.Context, cond *sync.Cond) (stop func()) {
+	done := ctx.Done()
+	if done == nil {
+		return func() {}
+	}
+	stopped := make(chan struct{})
+	go func() {
+		select {
+		case <-done:
+			cond.L.Lock()
+			cond.Broadcast()
+			cond.L.Unlock()
+		case <-stopped:
+		}
+	}()
+	return func() { close(stopped) }
+}
+
+// waitContext waits on cond until ready returns true or ctx is done,
+// cond.L must be locked by the caller.
+func waitContext(
+	ctx context.Context, cond *sync.Cond, ready func() bool) (err error) {
+	if ready() {
+		return nil
+	}
+	stop := watchContext(ctx, cond)
+	for !ready() {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		cond.Wait()
+	}
+	stop()
+	return
+}
+
+var clientFactories = struct {
+	sync.RWMutex
+	factories map[string]func(uri ...string) Client
+}{factories: make(map[string]func(uri ...string) Client)}
+
+// RegisterClientFactory registers a client factory for the uri scheme,
+// the factory registered later will replace the earlier one.
+//
+// For example, use FastHTTPClient instead of HTTPClient for http scheme:
+//
+//	rpc.RegisterClientFactory("http", func(uri ...string) rpc.Client {
+//		return rpc.NewFastHTTPClient(uri...)
+//	})
+func RegisterClientFactory(scheme string, factory func(uri ...string) Client) {
+	clientFactories.Lock()
+	clientFactories.factories[strings.ToLower(scheme)] = factory
+	clientFactories.Unlock()
+}
+
+// NewClient is the constructor of Client, the transport of the client is
+// chosen by the scheme of the first uri.
+//
+// The supported schemes are http, https, ws, wss, tcp, tcp4, tcp6 and unix,
+// you can add the other schemes by RegisterClientFactory.
+func NewClient(uri ...string) Client {
+	if len(uri) == 0 {
+		panic("NewClient: the uri can't be empty")
+	}
+	u, err := url.Parse(uri[0])
+	if err != nil {
+		panic("NewClient: the uri can't be parsed: " + err.Error())
+	}
+	clientFactories.RLock()
+	factory := clientFactories.factories[strings.ToLower(u.Scheme)]
+	clientFactories.RUnlock()
+	if factory == nil {
+		panic("NewClient: the " + u.Scheme + " client isn't implemented.")
+	}
+	return factory(uri...)
+}
+
+func init() {
+	RegisterClientFactory("http", newHTTPClient)
+	RegisterClientFactory("https", newHTTPClient)
+	RegisterClientFactory("ws", newWebSocketClient)
+	RegisterClientFactory("wss", newWebSocketClient)
+	RegisterClientFactory("tcp", newTCPClient)
+	RegisterClientFactory("tcp4", newTCPClient)
+	RegisterClientFactory("tcp6", newTCPClient)
+	RegisterClientFactory("unix", newUnixClient)
 }
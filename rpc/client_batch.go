@@ -0,0 +1,214 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/client_batch.go                                    *
+ *                                                        *
+ * hprose batch invocation for client.                    *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	hio "github.com/hprose/hprose-golang/io"
+)
+
+type batchCall struct {
+	request  []byte
+	settings *InvokeSettings
+	response chan socketResponse
+	uri      string
+}
+
+type batch struct {
+	cond    sync.Cond
+	calls   []*batchCall
+	pending int
+	sent    bool
+}
+
+func newBatch() *batch {
+	b := new(batch)
+	b.cond.L = &sync.Mutex{}
+	return b
+}
+
+// acquire a place in the batch for an invocation, it returns false if the
+// batch is already sent.
+func (b *batch) acquire() bool {
+	b.cond.L.Lock()
+	defer b.cond.L.Unlock()
+	if b.sent {
+		return false
+	}
+	b.pending++
+	return true
+}
+
+// release the place of the invocation which doesn't join the batch.
+func (b *batch) release() {
+	b.cond.L.Lock()
+	b.pending--
+	b.cond.L.Unlock()
+	b.cond.Broadcast()
+}
+
+// join the request to the batch and wait for the response.
+func (b *batch) join(
+	request []byte, context *ClientContext) ([]byte, error) {
+	call := &batchCall{
+		request:  request[:len(request)-1],
+		settings: &context.InvokeSettings,
+		response: make(chan socketResponse, 1),
+	}
+	b.cond.L.Lock()
+	b.calls = append(b.calls, call)
+	b.pending--
+	b.cond.L.Unlock()
+	b.cond.Broadcast()
+	select {
+	case resp := <-call.response:
+		context.uri = call.uri
+		return resp.data, resp.err
+	case <-context.ctx.Done():
+		return nil, context.ctx.Err()
+	}
+}
+
+// wait for all the acquired invocations, then returns the batch calls.
+func (b *batch) wait() []*batchCall {
+	b.cond.L.Lock()
+	for b.pending > 0 {
+		b.cond.Wait()
+	}
+	b.sent = true
+	calls := b.calls
+	b.cond.L.Unlock()
+	return calls
+}
+
+func getBatchSettings(calls []*batchCall) *InvokeSettings {
+	settings := &InvokeSettings{Idempotent: true}
+	for _, call := range calls {
+		s := call.settings
+		settings.Idempotent = settings.Idempotent && s.Idempotent
+		settings.Failswitch = settings.Failswitch || s.Failswitch
+		if s.Retry > settings.Retry {
+			settings.Retry = s.Retry
+		}
+		if s.Timeout > settings.Timeout {
+			settings.Timeout = s.Timeout
+		}
+	}
+	return settings
+}
+
+func getBatchRequest(calls []*batchCall) []byte {
+	writer := new(hio.ByteWriter)
+	for _, call := range calls {
+		writer.Write(call.request)
+	}
+	writer.WriteByte(hio.TagEnd)
+	return writer.Bytes()
+}
+
+// batchError returns the error of the whole batch if the response is a single
+// error, for example the batch fails in a filter of the server.
+func batchError(data []byte) (err error) {
+	if len(data) == 0 || data[0] != hio.TagError {
+		return nil
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			err = nil
+		}
+	}()
+	reader := hio.NewReader(data[1:], false)
+	message := reader.ReadString()
+	if tag, _ := reader.ReadByte(); tag != hio.TagEnd {
+		return nil
+	}
+	if _, e := reader.ReadByte(); e == nil {
+		return nil
+	}
+	return &ServerError{message}
+}
+
+// splitBatchResponse splits the merged response of the batch to n responses,
+// each of them ends with the end tag.
+func splitBatchResponse(data []byte, n int) (responses [][]byte, err error) {
+	if n > 1 {
+		if err = batchError(data); err != nil {
+			return nil, err
+		}
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("Wrong Response: \r\n%s", data)
+		}
+	}()
+	reader := hio.NewRawReader(data)
+	responses = make([][]byte, n)
+	for i := 0; i < n; i++ {
+		writer := new(hio.ByteWriter)
+		tag, _ := reader.ReadByte()
+		writer.WriteByte(tag)
+		switch tag {
+		case hio.TagResult:
+			reader.ReadRawTo(writer)
+			if tag, _ = reader.ReadByte(); tag == hio.TagArgument {
+				writer.WriteByte(tag)
+				reader.ReadRawTo(writer)
+			} else {
+				reader.UnreadByte()
+			}
+		case hio.TagError:
+			reader.ReadRawTo(writer)
+		default:
+			return nil, fmt.Errorf("Wrong Response: \r\n%s", data)
+		}
+		writer.WriteByte(hio.TagEnd)
+		responses[i] = writer.Bytes()
+	}
+	if tag, _ := reader.ReadByte(); tag != hio.TagEnd {
+		return nil, fmt.Errorf("Wrong Response: \r\n%s", data)
+	}
+	return
+}
+
+func (client *BaseClient) sendBatch(
+	ctx context.Context, calls []*batchCall) {
+	settings := getBatchSettings(calls)
+	context := client.acquireContext()
+	client.initClientContext(ctx, context, settings)
+	context.batched = true
+	response, err := client.sendRequest(getBatchRequest(calls), context)
+	var responses [][]byte
+	if err == nil {
+		responses, err = splitBatchResponse(response, len(calls))
+	}
+	for i, call := range calls {
+		call.uri = context.uri
+		if err != nil {
+			call.response <- socketResponse{nil, err}
+		} else {
+			call.response <- socketResponse{responses[i], nil}
+		}
+	}
+	context.ctx = nil
+	client.releaseContext(context)
+}
@@ -0,0 +1,104 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/client_batch_test.go                               *
+ *                                                        *
+ * hprose client batch test for Go.                       *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitBatchResponse(t *testing.T) {
+	tests := []struct {
+		data      string
+		n         int
+		responses []string
+		err       string
+	}{
+		{`Rs5"hello"Es6"failed"z`, 2,
+			[]string{`Rs5"hello"z`, `Es6"failed"z`}, ""},
+		{`Rs5"hello"Aa1{i1;}z`, 1, []string{`Rs5"hello"Aa1{i1;}z`}, ""},
+		{`Es6"failed"z`, 1, []string{`Es6"failed"z`}, ""},
+		{`Es8"rejected"z`, 2, nil, "rejected"},
+		{`Es6"failed"Es6"failed"z`, 2,
+			[]string{`Es6"failed"z`, `Es6"failed"z`}, ""},
+		{`Rs5"hello"z`, 2, nil, "Wrong Response"},
+		{`Rs5"hello"Rs5"hello"`, 2, nil, "Wrong Response"},
+	}
+	for _, test := range tests {
+		responses, err := splitBatchResponse([]byte(test.data), test.n)
+		if test.err != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), test.err) {
+				t.Errorf("%s: err is %v, want %s", test.data, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.data, err)
+			continue
+		}
+		got := make([]string, len(responses))
+		for i, response := range responses {
+			got[i] = string(response)
+		}
+		if !reflect.DeepEqual(got, test.responses) {
+			t.Errorf("%s: responses are %v, want %v",
+				test.data, got, test.responses)
+		}
+	}
+	if _, err := splitBatchResponse([]byte(`Es8"rejected"z`), 2); err != nil {
+		if _, ok := err.(*ServerError); !ok {
+			t.Errorf("err is %T, want *ServerError", err)
+		}
+	}
+}
+
+func TestBatchFailsInFilter(t *testing.T) {
+	server := NewTCPServer("")
+	server.AddFunction("hello", func(s string) string {
+		return "hello " + s
+	}, Options{})
+	server.AddBeforeFilterHandler(func(
+		request []byte, context Context, next NextFilterHandler) ([]byte, error) {
+		return nil, errors.New("rejected")
+	})
+	server.ErrorDelay = 0
+	server.Handle()
+	defer server.Close()
+	client := NewTCPClient(server.URI())
+	defer client.Close()
+	settings := &InvokeSettings{ResultTypes: []reflect.Type{stringType}}
+	errs := make(chan error, 2)
+	client.BeginBatch()
+	for _, name := range []string{"a", "b"} {
+		client.Go("hello", []reflect.Value{reflect.ValueOf(name)},
+			func(results []reflect.Value, err error) {
+				errs <- err
+			}, settings)
+	}
+	client.EndBatch()
+	for i := 0; i < 2; i++ {
+		err := <-errs
+		if e, ok := err.(*ServerError); !ok || e.Message != "rejected" {
+			t.Fatalf("err is %T %v, want *ServerError rejected", err, err)
+		}
+	}
+}
@@ -12,7 +12,7 @@
  *                                                        *
  * hprose client event for Go.                            *
  *                                                        *
- * LastModified: Sep 23, 2016                             *
+ * LastModified: Oct 18, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -29,3 +29,31 @@ type onErrorEvent interface {
 type onFailswitchEvent interface {
 	OnFailswitch(Client)
 }
+
+type onBreakerOpenEvent interface {
+	OnBreakerOpen(client Client, uri string)
+}
+
+type onBreakerCloseEvent interface {
+	OnBreakerClose(client Client, uri string)
+}
+
+type onHealthyEvent interface {
+	OnHealthy(client Client, uri string)
+}
+
+type onUnhealthyEvent interface {
+	OnUnhealthy(client Client, uri string)
+}
+
+type onResolveErrorEvent interface {
+	OnResolveError(client Client, err error)
+}
+
+type onConnectEvent interface {
+	OnConnect(client Client, uri string)
+}
+
+type onDisconnectEvent interface {
+	OnDisconnect(client Client, uri string, err error)
+}
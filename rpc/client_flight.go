@@ -0,0 +1,87 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/client_flight.go                                   *
+ *                                                        *
+ * hprose client singleflight for Go.                     *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"context"
+	"sync"
+)
+
+type flight struct {
+	done     chan struct{}
+	response []byte
+	uri      string
+	err      error
+}
+
+// flightGroup coalesces the concurrent invocations with the same request
+type flightGroup struct {
+	flights map[string]*flight
+	locker  sync.Mutex
+}
+
+// do sends the request by send, the concurrent invocations with the same
+// request share the round trip of the first one, and each of them gets its
+// own copy of the response.
+func (g *flightGroup) do(
+	request []byte,
+	clientContext *ClientContext,
+	send func() ([]byte, error)) ([]byte, error) {
+	key := string(request)
+	g.locker.Lock()
+	if f, ok := g.flights[key]; ok {
+		g.locker.Unlock()
+		select {
+		case <-f.done:
+		case <-clientContext.ctx.Done():
+			return nil, clientContext.ctx.Err()
+		}
+		// the first invocation is cancelled or timeout by its own context or
+		// settings, or it is aborted by a panic.
+		switch f.err {
+		case context.Canceled, context.DeadlineExceeded, ErrTimeout,
+			errFlightIsAborted:
+			return send()
+		}
+		clientContext.uri = f.uri
+		if f.err != nil {
+			return nil, f.err
+		}
+		return append([]byte(nil), f.response...), nil
+	}
+	if g.flights == nil {
+		g.flights = make(map[string]*flight)
+	}
+	f := &flight{done: make(chan struct{}), err: errFlightIsAborted}
+	g.flights[key] = f
+	g.locker.Unlock()
+	defer func() {
+		g.locker.Lock()
+		delete(g.flights, key)
+		g.locker.Unlock()
+		close(f.done)
+	}()
+	response, err := send()
+	f.response, f.uri, f.err = response, clientContext.uri, err
+	if f.err != nil {
+		return nil, f.err
+	}
+	return append([]byte(nil), f.response...), nil
+}
@@ -0,0 +1,122 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/client_flight_test.go                              *
+ *                                                        *
+ * hprose client flight test for Go.                      *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func newFlightContext() *ClientContext {
+	return &ClientContext{ctx: context.Background()}
+}
+
+// followFlight starts an invocation which is coalesced with the first one
+// after it is started.
+func followFlight(
+	g *flightGroup, request []byte, send func() ([]byte, error)) chan string {
+	result := make(chan string, 1)
+	go func() {
+		response, err := g.do(request, newFlightContext(), send)
+		if err != nil {
+			result <- err.Error()
+		} else {
+			result <- string(response)
+		}
+	}()
+	time.Sleep(50 * time.Millisecond)
+	return result
+}
+
+func TestFlightGroupShare(t *testing.T) {
+	var g flightGroup
+	release := make(chan struct{})
+	var result chan string
+	go func() {
+		result = followFlight(&g, []byte("request"), func() ([]byte, error) {
+			t.Error("the coalesced invocation is sent")
+			return nil, nil
+		})
+		close(release)
+	}()
+	response, err := g.do([]byte("request"), newFlightContext(),
+		func() ([]byte, error) {
+			<-release
+			return []byte("response"), nil
+		})
+	if err != nil || string(response) != "response" {
+		t.Fatal(string(response), err)
+	}
+	if r := <-result; r != "response" {
+		t.Fatal(r)
+	}
+}
+
+func TestFlightGroupLeaderFails(t *testing.T) {
+	leaderErrors := []error{ErrTimeout, context.DeadlineExceeded, context.Canceled}
+	for _, leaderError := range leaderErrors {
+		var g flightGroup
+		release := make(chan struct{})
+		var result chan string
+		go func() {
+			result = followFlight(&g, []byte("request"), func() ([]byte, error) {
+				return []byte("resent"), nil
+			})
+			close(release)
+		}()
+		g.do([]byte("request"), newFlightContext(), func() ([]byte, error) {
+			<-release
+			return nil, leaderError
+		})
+		if r := <-result; r != "resent" {
+			t.Fatalf("leader error: %v, follower gets %s", leaderError, r)
+		}
+	}
+}
+
+func TestFlightGroupLeaderPanics(t *testing.T) {
+	var g flightGroup
+	release := make(chan struct{})
+	var result chan string
+	go func() {
+		result = followFlight(&g, []byte("request"), func() ([]byte, error) {
+			return []byte("resent"), nil
+		})
+		close(release)
+	}()
+	func() {
+		defer func() {
+			if e := recover(); e != "panic" {
+				t.Fatalf("recover returns %v", e)
+			}
+		}()
+		g.do([]byte("request"), newFlightContext(), func() ([]byte, error) {
+			<-release
+			panic("panic")
+		})
+	}()
+	if r := <-result; r != "resent" {
+		t.Fatalf("follower gets %s", r)
+	}
+	if len(g.flights) != 0 {
+		t.Fatal("the flight isn't removed")
+	}
+}
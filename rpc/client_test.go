@@ -0,0 +1,60 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/client_test.go                                     *
+ *                                                        *
+ * hprose client test for Go.                             *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		uri    string
+		client Client
+	}{
+		{"http://localhost/", &HTTPClient{}},
+		{"HTTPS://localhost/", &HTTPClient{}},
+		{"ws://localhost/", &WebSocketClient{}},
+		{"wss://localhost/", &WebSocketClient{}},
+		{"tcp://localhost:4321", &TCPClient{}},
+		{"tcp4://localhost:4321", &TCPClient{}},
+		{"tcp6://localhost:4321", &TCPClient{}},
+		{"unix:/tmp/hprose.sock", &UnixClient{}},
+	}
+	for _, test := range tests {
+		client := NewClient(test.uri)
+		if reflect.TypeOf(client) != reflect.TypeOf(test.client) {
+			t.Errorf("NewClient(%q) is %T, want %T", test.uri, client, test.client)
+		}
+		client.Close()
+	}
+}
+
+func TestRegisterFastHTTPClientFactory(t *testing.T) {
+	RegisterClientFactory("http", func(uri ...string) Client {
+		return NewFastHTTPClient(uri...)
+	})
+	defer RegisterClientFactory("http", newHTTPClient)
+	client := NewClient("http://localhost/")
+	defer client.Close()
+	if _, ok := client.(*FastHTTPClient); !ok {
+		t.Errorf("NewClient returns %T, want *FastHTTPClient", client)
+	}
+}
@@ -0,0 +1,60 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/client_topic.go                                    *
+ *                                                        *
+ * hprose push topic for client.                          *
+ *                                                        *
+ * LastModified: Oct 17, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+type clientTopic struct {
+	sync.RWMutex
+	callbacks []reflect.Value
+	stopped   int32
+	cancel    context.CancelFunc
+}
+
+func newClientTopic(cancel context.CancelFunc) *clientTopic {
+	return &clientTopic{cancel: cancel}
+}
+
+func (t *clientTopic) addCallback(callback reflect.Value) {
+	t.Lock()
+	t.callbacks = append(t.callbacks, callback)
+	t.Unlock()
+}
+
+func (t *clientTopic) getCallbacks() (callbacks []reflect.Value) {
+	t.RLock()
+	callbacks = t.callbacks
+	t.RUnlock()
+	return
+}
+
+func (t *clientTopic) stop() {
+	atomic.StoreInt32(&t.stopped, 1)
+	t.cancel()
+}
+
+func (t *clientTopic) isStopped() bool {
+	return atomic.LoadInt32(&t.stopped) != 0
+}
@@ -12,7 +12,7 @@
  *                                                        *
  * rpc error for Go.                                      *
  *                                                        *
- * LastModified: Oct 2, 2016                              *
+ * LastModified: Oct 18, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -27,9 +27,64 @@ import (
 
 // ErrTimeout represents a timeout error
 var ErrTimeout = errors.New("timeout")
+
+// ErrCircuitOpen represents the circuit breakers of the service addresses are
+// open, the request is not sent.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// ErrNoServiceAddress represents the resolver returns no service address
+var ErrNoServiceAddress = errors.New("no service address is resolved")
+
+// ErrContentTooLarge represents the decompressed content exceeds the limit
+var ErrContentTooLarge = errors.New("decompressed content is too large")
+
+// ErrTooManyQueuedCalls represents the call is rejected because too many
+// calls are waiting for the connection
+var ErrTooManyQueuedCalls = errors.New("too many calls are waiting for the connection")
+
+// ErrHeartbeatTimeout represents nothing is received from the websocket peer
+// in time, the connection is considered dead
+var ErrHeartbeatTimeout = errors.New("websocket heartbeat timeout")
+
 var errServerIsAlreadyStarted = errors.New("The server is already started")
 var errServerIsNotStarted = errors.New("The server is not started")
 var errClientIsAlreadyClosed = errors.New("The Client is already closed")
+var errFlightIsAborted = errors.New("The coalesced invocation is aborted")
+var errServiceAddressIsRemoved = errors.New("The service address is removed")
+
+func isClosedError(err error) bool {
+	if e, ok := err.(*PanicError); ok {
+		err, _ = e.Panic.(error)
+	}
+	return err == errClientIsAlreadyClosed
+}
+
+// ServerError represents the error returned by the hprose server
+type ServerError struct {
+	Message string
+}
+
+// Error implements the ServerError Error method.
+func (se *ServerError) Error() string {
+	return se.Message
+}
+
+// DialError represents the connection to the service address can't be
+// established
+type DialError struct {
+	URI string
+	Err error
+}
+
+// Error implements the DialError Error method.
+func (e *DialError) Error() string {
+	return "dial " + e.URI + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error of DialError
+func (e *DialError) Unwrap() error {
+	return e.Err
+}
 
 // PanicError represents a panic error
 type PanicError struct {
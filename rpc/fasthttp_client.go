@@ -12,7 +12,7 @@
  *                                                        *
  * hprose http client for Go.                             *
  *                                                        *
- * LastModified: Oct 2, 2016                              *
+ * LastModified: Oct 18, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -20,6 +20,8 @@
 package rpc
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
 
 	"github.com/valyala/fasthttp"
@@ -29,28 +31,29 @@ import (
 type FastHTTPClient struct {
 	BaseClient
 	limiter
+	compressor
 	fasthttp.Client
 	Header      fasthttp.RequestHeader
 	compression bool
 	keepAlive   bool
 }
 
-// NewFastHTTPClient is the constructor of FastHTTPClient
+// NewFastHTTPClient is the constructor of FastHTTPClient,
+// NewClient creates HTTPClient for http and https schemes,
+// use RegisterClientFactory to create FastHTTPClient instead.
 func NewFastHTTPClient(uri ...string) (client *FastHTTPClient) {
 	client = new(FastHTTPClient)
 	client.initBaseClient()
 	client.initLimiter()
+	client.initCompressor()
 	client.compression = false
 	client.keepAlive = true
+	client.setURIList = client.SetURIList
 	client.SetURIList(uri)
 	client.SendAndReceive = client.sendAndReceive
 	return
 }
 
-func newFastHTTPClient(uri ...string) Client {
-	return NewFastHTTPClient(uri...)
-}
-
 // SetURIList set a list of server addresses
 func (client *FastHTTPClient) SetURIList(uriList []string) {
 	checkHTTPAddresses(client, uriList)
@@ -77,20 +80,34 @@ func (client *FastHTTPClient) Compression() bool {
 	return client.compression
 }
 
-// SetCompression set the compression status of hprose client
+// SetCompression set the compression status of hprose client, the requests
+// which are not smaller than CompressionMinSize are compressed by gzip, and
+// the responses are accepted in gzip or deflate.
 func (client *FastHTTPClient) SetCompression(enable bool) {
 	client.compression = enable
 }
 
 func (client *FastHTTPClient) sendAndReceive(
 	data []byte, context *ClientContext) ([]byte, error) {
+	compressed := client.compression && client.shouldCompress(data)
+	if compressed {
+		var err error
+		if data, err = client.compress(data, "gzip"); err != nil {
+			return nil, err
+		}
+	}
+	ctx, cancel := withTimeout(context.Context(), context.Timeout)
+	defer cancel()
 	client.cond.L.Lock()
-	client.limit()
+	err := client.limit(ctx)
 	client.cond.L.Unlock()
+	if err != nil {
+		return nil, timeoutError(context.Context())
+	}
 	req := fasthttp.AcquireRequest()
 	client.Header.CopyTo(&req.Header)
 	req.Header.SetMethod("POST")
-	req.SetRequestURI(client.uri)
+	req.SetRequestURI(context.uri)
 	req.SetBody(data)
 	req.Header.SetContentLength(len(data))
 	req.Header.SetContentType("application/hprose")
@@ -100,19 +117,52 @@ func (client *FastHTTPClient) sendAndReceive(
 		req.Header.Set("Connection", "close")
 	}
 	if client.compression {
-		req.Header.Set("Content-Encoding", "gzip")
+		req.Header.Set("Accept-Encoding", acceptedEncodings)
 	}
-	resp := fasthttp.AcquireResponse()
-	err := client.Client.DoTimeout(req, resp, context.Timeout)
-	if err != nil {
-		data = nil
-	} else {
-		data = resp.Body()
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
 	}
-	fasthttp.ReleaseRequest(req)
-	fasthttp.ReleaseResponse(resp)
+	data, err = client.do(ctx, req)
 	client.cond.L.Lock()
 	client.unlimit()
 	client.cond.L.Unlock()
+	if err == fasthttp.ErrTimeout || ctx.Err() != nil {
+		err = timeoutError(context.Context())
+	}
 	return data, err
 }
+
+// do sends the request and releases it, fasthttp can't abort a request,
+// so the request is finished in background when ctx is cancelled.
+func (client *FastHTTPClient) do(
+	ctx context.Context, req *fasthttp.Request) ([]byte, error) {
+	done := make(chan socketResponse, 1)
+	go func() {
+		var data []byte
+		var err error
+		resp := fasthttp.AcquireResponse()
+		if deadline, ok := ctx.Deadline(); ok {
+			err = client.Client.DoDeadline(req, resp, deadline)
+		} else {
+			err = client.Client.Do(req, resp)
+		}
+		if err == nil {
+			encoding := resp.Header.Peek("Content-Encoding")
+			if len(encoding) == 0 {
+				data = append([]byte(nil), resp.Body()...)
+			} else {
+				data, err = client.decompress(
+					bytes.NewReader(resp.Body()), string(encoding))
+			}
+		}
+		fasthttp.ReleaseRequest(req)
+		fasthttp.ReleaseResponse(resp)
+		done <- socketResponse{data, err}
+	}()
+	select {
+	case resp := <-done:
+		return resp.data, resp.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -12,7 +12,7 @@
  *                                                        *
  * hprose fasthttp service for Go.                        *
  *                                                        *
- * LastModified: Oct 6, 2016                              *
+ * LastModified: Oct 18, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -20,6 +20,7 @@
 package rpc
 
 import (
+	"bytes"
 	"reflect"
 	"runtime"
 	"strings"
@@ -202,13 +203,38 @@ func (service *FastHTTPService) ServeFastHTTP(ctx *fasthttp.RequestCtx) {
 				ctx.SetStatusCode(403)
 			}
 		case "POST":
-			resp = service.Handle(ctx.PostBody(), context)
+			if req, err := service.readRequest(ctx); err == nil {
+				resp = service.Handle(req, context)
+			} else {
+				resp = service.endError(err, context)
+			}
 		}
 	} else {
 		resp = service.endError(err, context)
 	}
 	context.RequestCtx = nil
 	service.releaseContext(context)
+	if service.Compression {
+		ctx.Response.Header.Add("Vary", "Accept-Encoding")
+		var encoding string
+		resp, encoding = service.compressResponse(resp,
+			util.ByteString(ctx.Request.Header.Peek("Accept-Encoding")))
+		if encoding != "" {
+			ctx.Response.Header.Set("Content-Encoding", encoding)
+		}
+	}
 	ctx.Response.Header.Set("Content-Length", util.Itoa(len(resp)))
 	ctx.SetBody(resp)
 }
+
+// readRequest reads the request body, and decompresses it by the
+// Content-Encoding header.
+func (service *FastHTTPService) readRequest(
+	ctx *fasthttp.RequestCtx) ([]byte, error) {
+	encoding := ctx.Request.Header.Peek("Content-Encoding")
+	if len(encoding) == 0 {
+		return ctx.PostBody(), nil
+	}
+	return service.decompress(
+		bytes.NewReader(ctx.PostBody()), string(encoding))
+}
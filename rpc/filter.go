@@ -12,7 +12,7 @@
  *                                                        *
  * hprose filter interface for Go.                        *
  *                                                        *
- * LastModified: Oct 2, 2016                              *
+ * LastModified: Oct 18, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -57,8 +57,7 @@ func (fm *filterManager) FilterByIndex(index int) Filter {
 // SetFilter will replace the current filter settings
 func (fm *filterManager) SetFilter(filter ...Filter) {
 	fm.fmLocker.Lock()
-	fm.filters = make([]Filter, len(filter))
-	fm.AddFilter(filter...)
+	fm.filters = append([]Filter(nil), filter...)
 	fm.fmLocker.Unlock()
 }
 
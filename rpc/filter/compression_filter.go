@@ -0,0 +1,239 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/filter/compression_filter.go                       *
+ *                                                        *
+ * hprose compression filter for Go.                      *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package filter
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	hio "github.com/hprose/hprose-golang/io"
+	"github.com/hprose/hprose-golang/rpc"
+)
+
+// Algorithm is the compression algorithm of CompressionFilter
+type Algorithm byte
+
+// The compression algorithms, they are also the markers of the compressed
+// data, and Plain marks the data which is too small to be compressed.
+const (
+	Plain Algorithm = iota
+	Gzip
+	Flate
+)
+
+// DefaultMinSize is the default MinSize of CompressionFilter
+const DefaultMinSize = 1024
+
+// DefaultMaxSize is the default MaxSize of CompressionFilter, 64 MiB.
+const DefaultMaxSize = 64 << 20
+
+// errorPrefix is the prefix of the errors of CompressionFilter, the client
+// uses it to tell the errors of the server filter from the mismatch errors.
+const errorPrefix = "hprose/filter: "
+
+// acceptedKey is the context key which records that the request is
+// compressed by CompressionFilter.
+const acceptedKey = "hprose/filter.compression"
+
+// ErrTooLarge represents the decompressed data exceeds MaxSize
+var ErrTooLarge = errors.New(errorPrefix + "decompressed data is too large")
+
+// ErrNotCompressed represents the request is not compressed by
+// CompressionFilter, the client doesn't use it.
+var ErrNotCompressed = errors.New(errorPrefix +
+	"the request is not compressed by CompressionFilter, " +
+	"the client may not use it")
+
+// MismatchError represents the response is not compressed by
+// CompressionFilter, the server doesn't use it. Response is the error
+// message of the server if it responds an error.
+type MismatchError struct {
+	Response string
+}
+
+// Error implements the MismatchError Error method.
+func (e *MismatchError) Error() string {
+	msg := errorPrefix + "the response is not compressed by " +
+		"CompressionFilter, the server may not use it"
+	if e.Response != "" {
+		msg += ": " + e.Response
+	}
+	return msg
+}
+
+// CompressionFilter compresses the requests and the responses of the
+// hprose clients and services, it is used on TCP, Unix and WebSocket where
+// HTTP content-encoding does not apply. Both of the client and the service
+// must use it. Every message begins with one byte marker of the algorithm,
+// so the peers can use the different algorithms, and the messages which are
+// smaller than MinSize pass through without compression.
+type CompressionFilter struct {
+	Algorithm Algorithm
+	// Level is the compression level of gzip and flate.
+	Level int
+	// MinSize is the minimum size of the compressed message.
+	MinSize int
+	// MaxSize is the maximum size of the decompressed message, it guards
+	// against the zip bombs, 0 means no limit.
+	MaxSize int64
+	// AcceptPlain allows the service to accept the requests of the clients
+	// which don't use CompressionFilter, the responses are not compressed
+	// either, it is useful when the clients are upgraded one by one.
+	AcceptPlain bool
+}
+
+// NewCompressionFilter is the constructor of CompressionFilter
+func NewCompressionFilter(algorithm Algorithm) *CompressionFilter {
+	if algorithm != Gzip && algorithm != Flate {
+		panic("CompressionFilter: unsupported algorithm " +
+			strconv.Itoa(int(algorithm)))
+	}
+	return &CompressionFilter{
+		Algorithm: algorithm,
+		Level:     flate.DefaultCompression,
+		MinSize:   DefaultMinSize,
+		MaxSize:   DefaultMaxSize,
+	}
+}
+
+// InputFilter decompresses the request on the service side, and the
+// response on the client side. The client panics with MismatchError if the
+// response is not compressed, and the error of the service filter and the
+// empty response of a failed request are passed through.
+func (filter *CompressionFilter) InputFilter(
+	data []byte, context rpc.Context) []byte {
+	if _, ok := context.(rpc.ServiceContext); !ok {
+		if len(data) == 0 {
+			return data
+		}
+		if !isMarked(data) {
+			msg := errorMessage(data)
+			if strings.HasPrefix(msg, errorPrefix) {
+				return data
+			}
+			panic(&MismatchError{msg})
+		}
+		return filter.decode(data)
+	}
+	if isMarked(data) {
+		context.SetBool(acceptedKey, true)
+		return filter.decode(data)
+	}
+	if !filter.AcceptPlain {
+		panic(ErrNotCompressed)
+	}
+	return data
+}
+
+// OutputFilter compresses the request on the client side, and the response
+// on the service side if the request is compressed.
+func (filter *CompressionFilter) OutputFilter(
+	data []byte, context rpc.Context) []byte {
+	if _, ok := context.(rpc.ServiceContext); ok {
+		if !context.GetBool(acceptedKey) {
+			return data
+		}
+	}
+	return filter.encode(data)
+}
+
+// isMarked reports whether data begins with a marker, the markers never
+// conflict with the hprose tags.
+func isMarked(data []byte) bool {
+	return len(data) > 0 && Algorithm(data[0]) <= Flate
+}
+
+// errorMessage returns the error message if data is an hprose error
+// response
+func errorMessage(data []byte) (msg string) {
+	if len(data) == 0 || data[0] != hio.TagError {
+		return ""
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			msg = ""
+		}
+	}()
+	reader := hio.NewReader(data[1:], false)
+	return reader.ReadString()
+}
+
+func (filter *CompressionFilter) encode(data []byte) []byte {
+	if len(data) < filter.MinSize {
+		return append([]byte{byte(Plain)}, data...)
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(byte(filter.Algorithm))
+	var w io.WriteCloser
+	var err error
+	switch filter.Algorithm {
+	case Gzip:
+		w, err = gzip.NewWriterLevel(&buf, filter.Level)
+	case Flate:
+		w, err = flate.NewWriter(&buf, filter.Level)
+	default:
+		err = errors.New(errorPrefix + "unsupported algorithm " +
+			strconv.Itoa(int(filter.Algorithm)))
+	}
+	if err != nil {
+		panic(err)
+	}
+	if _, err = w.Write(data); err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+func (filter *CompressionFilter) decode(data []byte) []byte {
+	var r io.ReadCloser
+	var err error
+	switch Algorithm(data[0]) {
+	case Plain:
+		return data[1:]
+	case Gzip:
+		r, err = gzip.NewReader(bytes.NewReader(data[1:]))
+	case Flate:
+		r = flate.NewReader(bytes.NewReader(data[1:]))
+	}
+	if err != nil {
+		panic(errors.New(errorPrefix + err.Error()))
+	}
+	defer r.Close()
+	if filter.MaxSize > 0 {
+		r = ioutil.NopCloser(io.LimitReader(r, filter.MaxSize+1))
+	}
+	data, err = ioutil.ReadAll(r)
+	if err != nil {
+		panic(errors.New(errorPrefix + err.Error()))
+	}
+	if filter.MaxSize > 0 && int64(len(data)) > filter.MaxSize {
+		panic(ErrTooLarge)
+	}
+	return data
+}
@@ -0,0 +1,146 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/filter/compression_filter_test.go                  *
+ *                                                        *
+ * hprose compression filter test for Go.                 *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package filter
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hprose/hprose-golang/rpc"
+)
+
+type spyFilter struct {
+	markers []byte
+}
+
+func (spy *spyFilter) InputFilter(data []byte, context rpc.Context) []byte {
+	return data
+}
+
+func (spy *spyFilter) OutputFilter(data []byte, context rpc.Context) []byte {
+	spy.markers = append(spy.markers, data[0])
+	return data
+}
+
+func newServer(filter rpc.Filter) *rpc.TCPServer {
+	server := rpc.NewTCPServer("")
+	server.AddFunction("echo", func(s string) string { return s }, rpc.Options{})
+	server.ErrorDelay = 0
+	if filter != nil {
+		server.AddFilter(filter)
+	}
+	server.Handle()
+	return server
+}
+
+func echo(client rpc.Client, s string) (string, error) {
+	args := []reflect.Value{reflect.ValueOf(s)}
+	settings := &rpc.InvokeSettings{
+		ResultTypes: []reflect.Type{reflect.TypeOf("")},
+	}
+	results, err := client.Invoke("echo", args, settings)
+	if err != nil {
+		return "", err
+	}
+	return results[0].String(), nil
+}
+
+func TestCompressionFilter(t *testing.T) {
+	large := strings.Repeat("hprose", 1000)
+	for _, algorithm := range []Algorithm{Gzip, Flate} {
+		server := newServer(NewCompressionFilter(algorithm))
+		for _, clientAlgorithm := range []Algorithm{Gzip, Flate} {
+			client := rpc.NewTCPClient(server.URI())
+			spy := &spyFilter{}
+			client.AddFilter(NewCompressionFilter(clientAlgorithm), spy)
+			for _, s := range []string{"hello", large} {
+				if result, err := echo(client, s); err != nil || result != s {
+					t.Fatal(algorithm, clientAlgorithm, err)
+				}
+			}
+			if spy.markers[0] != byte(Plain) ||
+				spy.markers[1] != byte(clientAlgorithm) {
+				t.Error("wrong markers", spy.markers)
+			}
+			client.Close()
+		}
+		server.Close()
+	}
+}
+
+func TestCompressionFilterMismatch(t *testing.T) {
+	server := newServer(nil)
+	client := rpc.NewTCPClient(server.URI())
+	client.AddFilter(NewCompressionFilter(Gzip))
+	_, err := echo(client, "hello")
+	if err == nil || !strings.Contains(err.Error(), "server may not use it") {
+		t.Error(err)
+	}
+	client.Close()
+	server.Close()
+
+	filter := NewCompressionFilter(Gzip)
+	server = newServer(filter)
+	client = rpc.NewTCPClient(server.URI())
+	if _, err = echo(client, "hello"); err == nil ||
+		err.Error() != ErrNotCompressed.Error() {
+		t.Error(err)
+	}
+	filter.AcceptPlain = true
+	if result, err := echo(client, "hello"); err != nil || result != "hello" {
+		t.Error(err)
+	}
+	client.Close()
+	server.Close()
+}
+
+func TestCompressionFilterMaxSize(t *testing.T) {
+	filter := NewCompressionFilter(Flate)
+	filter.MaxSize = 100
+	server := newServer(filter)
+	client := rpc.NewTCPClient(server.URI())
+	client.AddFilter(NewCompressionFilter(Flate))
+	large := strings.Repeat("hprose", 1000)
+	if _, err := echo(client, large); err == nil ||
+		err.Error() != ErrTooLarge.Error() {
+		t.Error(err)
+	}
+	client.Close()
+	server.Close()
+}
+
+func TestCompressionFilterDialError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := "tcp://" + listener.Addr().String()
+	listener.Close()
+	client := rpc.NewTCPClient(uri)
+	client.SetRetry(1)
+	client.AddFilter(NewCompressionFilter(Gzip))
+	_, err = echo(client, "hello")
+	if _, ok := err.(*rpc.DialError); !ok {
+		t.Errorf("%T: %v, want *rpc.DialError", err, err)
+	}
+	client.Close()
+}
@@ -0,0 +1,24 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/filter/doc.go                                      *
+ *                                                        *
+ * hprose filter doc for Go.                              *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+/*
+Package filter provides the hprose filters which transform the requests and
+the responses of the hprose clients and services.
+*/
+package filter
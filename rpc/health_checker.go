@@ -0,0 +1,205 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/health_checker.go                                  *
+ *                                                        *
+ * hprose client health checker for Go.                   *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"sync"
+	"time"
+)
+
+type healthEndpoint struct {
+	healthy   bool
+	successes int
+	failures  int
+}
+
+// HealthChecker probes every service address of the client in the background
+// at Interval by calling Method. A service address becomes unhealthy after
+// UnhealthyThreshold consecutive failed probes, and healthy again after
+// HealthyThreshold consecutive successful probes. The unhealthy service
+// addresses are skipped by the balancer and failswitch.
+//
+// A HealthChecker can't be shared by clients.
+type HealthChecker struct {
+	// Interval is the interval of the probes, 10s by default.
+	Interval time.Duration
+	// Timeout is the timeout of each probe, 5s by default.
+	Timeout time.Duration
+	// Method is the remote method called by the probes, the built-in "#"
+	// function by default.
+	Method string
+	// HealthyThreshold is the number of the consecutive successful probes
+	// before an unhealthy service address becomes healthy, 1 by default.
+	HealthyThreshold int
+	// UnhealthyThreshold is the number of the consecutive failed probes
+	// before a healthy service address becomes unhealthy, 3 by default.
+	UnhealthyThreshold int
+	locker             sync.Mutex
+	endpoints          map[string]*healthEndpoint
+	done               chan struct{}
+	changed            func(uri string, healthy bool)
+	probe              func(uri string) error
+}
+
+// NewHealthChecker is the constructor of HealthChecker
+func NewHealthChecker() *HealthChecker {
+	return &HealthChecker{
+		Interval:           10 * time.Second,
+		Timeout:            5 * time.Second,
+		Method:             "#",
+		HealthyThreshold:   1,
+		UnhealthyThreshold: 3,
+		endpoints:          make(map[string]*healthEndpoint),
+	}
+}
+
+// init sets the zero fields of checker to the defaults
+func (checker *HealthChecker) init() {
+	checker.locker.Lock()
+	if checker.Interval <= 0 {
+		checker.Interval = 10 * time.Second
+	}
+	if checker.Timeout <= 0 {
+		checker.Timeout = 5 * time.Second
+	}
+	if checker.Method == "" {
+		checker.Method = "#"
+	}
+	if checker.HealthyThreshold <= 0 {
+		checker.HealthyThreshold = 1
+	}
+	if checker.UnhealthyThreshold <= 0 {
+		checker.UnhealthyThreshold = 3
+	}
+	if checker.endpoints == nil {
+		checker.endpoints = make(map[string]*healthEndpoint)
+	}
+	checker.locker.Unlock()
+}
+
+// Healthy returns false if the service address is unhealthy
+func (checker *HealthChecker) Healthy(uri string) bool {
+	checker.locker.Lock()
+	defer checker.locker.Unlock()
+	if endpoint := checker.endpoints[uri]; endpoint != nil {
+		return endpoint.healthy
+	}
+	return true
+}
+
+func (checker *HealthChecker) setURIList(uriList []string) {
+	uris := make(map[string]bool, len(uriList))
+	checker.locker.Lock()
+	for _, uri := range uriList {
+		uris[uri] = true
+		if checker.endpoints[uri] == nil {
+			checker.endpoints[uri] = &healthEndpoint{healthy: true}
+		}
+	}
+	for uri := range checker.endpoints {
+		if !uris[uri] {
+			delete(checker.endpoints, uri)
+		}
+	}
+	checker.locker.Unlock()
+}
+
+func (checker *HealthChecker) start() {
+	done := make(chan struct{})
+	checker.locker.Lock()
+	checker.done = done
+	checker.locker.Unlock()
+	go func() {
+		ticker := time.NewTicker(checker.Interval)
+		defer ticker.Stop()
+		for {
+			checker.check(done)
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (checker *HealthChecker) stop() {
+	checker.locker.Lock()
+	if checker.done != nil {
+		close(checker.done)
+		checker.done = nil
+	}
+	checker.locker.Unlock()
+}
+
+// check probes all of the service addresses concurrently
+func (checker *HealthChecker) check(done chan struct{}) {
+	checker.locker.Lock()
+	uriList := make([]string, 0, len(checker.endpoints))
+	for uri := range checker.endpoints {
+		uriList = append(uriList, uri)
+	}
+	checker.locker.Unlock()
+	var wg sync.WaitGroup
+	for _, uri := range uriList {
+		wg.Add(1)
+		go func(uri string) {
+			defer wg.Done()
+			err := checker.probe(uri)
+			select {
+			case <-done:
+			default:
+				checker.report(uri, err)
+			}
+		}(uri)
+	}
+	wg.Wait()
+}
+
+// report records the result of the probe
+func (checker *HealthChecker) report(uri string, err error) {
+	checker.locker.Lock()
+	endpoint := checker.endpoints[uri]
+	if endpoint == nil {
+		checker.locker.Unlock()
+		return
+	}
+	changed := false
+	if err == nil {
+		endpoint.failures = 0
+		endpoint.successes++
+		if !endpoint.healthy && endpoint.successes >= checker.HealthyThreshold {
+			endpoint.healthy = true
+			changed = true
+		}
+	} else {
+		endpoint.successes = 0
+		endpoint.failures++
+		if endpoint.healthy && endpoint.failures >= checker.UnhealthyThreshold {
+			endpoint.healthy = false
+			changed = true
+		}
+	}
+	healthy := endpoint.healthy
+	checker.locker.Unlock()
+	if changed && checker.changed != nil {
+		checker.changed(uri, healthy)
+	}
+}
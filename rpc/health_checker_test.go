@@ -0,0 +1,126 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/health_checker_test.go                             *
+ *                                                        *
+ * hprose health checker test for Go.                     *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHealthCheckerThresholds(t *testing.T) {
+	const uri = "tcp://127.0.0.1:1"
+	failure := errors.New("failure")
+	tests := []struct {
+		healthy   int
+		unhealthy int
+		// probes is the results of the probes, 's' is a success and 'f' is a
+		// failure.
+		probes  string
+		changes []bool
+	}{
+		{1, 3, "ssfsff", nil},
+		{1, 3, "fffs", []bool{false, true}},
+		{1, 3, "ffsfffs", []bool{false, true}},
+		{2, 1, "fsfss", []bool{false, true}},
+		{3, 2, "ffssfsssffsss", []bool{false, true, false, true}},
+	}
+	for _, test := range tests {
+		checker := NewHealthChecker()
+		checker.HealthyThreshold = test.healthy
+		checker.UnhealthyThreshold = test.unhealthy
+		var results []error
+		for _, probe := range test.probes {
+			if probe == 's' {
+				results = append(results, nil)
+			} else {
+				results = append(results, failure)
+			}
+		}
+		checker.probe = func(u string) (err error) {
+			err, results = results[0], results[1:]
+			return
+		}
+		var changes []bool
+		checker.changed = func(u string, healthy bool) {
+			changes = append(changes, healthy)
+		}
+		checker.setURIList([]string{uri})
+		done := make(chan struct{})
+		for range test.probes {
+			checker.check(done)
+		}
+		if !reflect.DeepEqual(changes, test.changes) {
+			t.Errorf("thresholds: %d/%d, probes: %s, changes are %v, want %v",
+				test.healthy, test.unhealthy, test.probes, changes, test.changes)
+		}
+		want := true
+		if n := len(test.changes); n > 0 {
+			want = test.changes[n-1]
+		}
+		if checker.Healthy(uri) != want {
+			t.Errorf("probes: %s, Healthy is %v, want %v",
+				test.probes, !want, want)
+		}
+	}
+}
+
+func waitHealthy(
+	t *testing.T, checker *HealthChecker, uri string, healthy bool) {
+	deadline := time.Now().Add(time.Second)
+	for checker.Healthy(uri) != healthy {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s: Healthy is %v, want %v", uri, !healthy, healthy)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHealthCheckerTransitions(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri1 := "tcp://" + listener.Addr().String()
+	listener.Close()
+	server2 := NewTCPServer("")
+	server2.Handle()
+	defer server2.Close()
+	uri2 := server2.URI()
+	client := NewTCPClient(uri1, uri2)
+	defer client.Close()
+	checker := &HealthChecker{Interval: 10 * time.Millisecond}
+	client.SetHealthChecker(checker)
+	if checker.Timeout != 5*time.Second || checker.Method != "#" ||
+		checker.HealthyThreshold != 1 || checker.UnhealthyThreshold != 3 {
+		t.Fatalf("the defaults are not set: %+v", checker)
+	}
+	waitHealthy(t, checker, uri1, false)
+	waitHealthy(t, checker, uri2, true)
+	if uri := client.URI(); uri != uri2 {
+		t.Fatalf("the current uri is %s, want %s", uri, uri2)
+	}
+	server1 := NewTCPServer(uri1)
+	server1.Handle()
+	defer server1.Close()
+	waitHealthy(t, checker, uri1, true)
+}
@@ -0,0 +1,232 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/http2_transport.go                                 *
+ *                                                        *
+ * hprose http2 transport for Go.                         *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"golang.org/x/net/http2"
+)
+
+// http2Transport sends the requests of HTTPClient when HTTP/2 is enabled.
+// Every service address is connected by one multiplexed connection, the
+// http addresses are connected by HTTP/2 cleartext (h2c) with prior
+// knowledge, and the https addresses negotiate HTTP/2 by ALPN and fall back
+// to the HTTP/1.1 transport if the server doesn't support it.
+//
+// The concurrent requests of a connection are limited by the
+// SETTINGS_MAX_CONCURRENT_STREAMS of the server, the requests exceeding it
+// wait for a free stream instead of opening another connection.
+type http2Transport struct {
+	h1     *http.Transport
+	h2     *http2.Transport
+	locker sync.Mutex
+	conns  map[string]*http2Conn
+}
+
+// http2Conn is the connection of a service address, conn is nil if the
+// server doesn't support HTTP/2, ready is closed when it is dialed.
+type http2Conn struct {
+	ready chan struct{}
+	conn  *http2.ClientConn
+	err   error
+}
+
+func newHTTP2Transport(h1 *http.Transport) *http2Transport {
+	return &http2Transport{
+		h1: h1,
+		h2: &http2.Transport{
+			AllowHTTP: true,
+			// the response is decompressed by HTTPClient.
+			DisableCompression:         true,
+			StrictMaxConcurrentStreams: true,
+		},
+		conns: make(map[string]*http2Conn),
+	}
+}
+
+// http2HostPort returns the host and port of u
+func http2HostPort(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// http2Addr returns the scheme, host and port of u, the connection is
+// shared by the service addresses with the same http2Addr.
+func http2Addr(u *url.URL) string {
+	return u.Scheme + "://" + http2HostPort(u)
+}
+
+// multiplexed returns true if the requests to uri are sent by HTTP/2, it
+// connects to uri if the protocol is not negotiated yet.
+func (t *http2Transport) multiplexed(
+	ctx context.Context, uri string) (bool, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false, err
+	}
+	conn, err := t.getConn(ctx, u)
+	return conn != nil, err
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t *http2Transport) RoundTrip(
+	request *http.Request) (*http.Response, error) {
+	conn, err := t.getConn(request.Context(), request.URL)
+	if err != nil {
+		return nil, err
+	}
+	if conn == nil {
+		return t.h1.RoundTrip(request)
+	}
+	return conn.RoundTrip(request)
+}
+
+// getConn returns the HTTP/2 connection of u, it dials the connection if
+// there is no usable one, and returns nil if the server doesn't support
+// HTTP/2.
+func (t *http2Transport) getConn(
+	ctx context.Context, u *url.URL) (*http2.ClientConn, error) {
+	addr := http2Addr(u)
+	t.locker.Lock()
+	c := t.conns[addr]
+	if c != nil {
+		select {
+		case <-c.ready:
+			if c.conn != nil && !isHTTP2ConnUsable(c.conn) {
+				c = nil
+			}
+		default:
+		}
+	}
+	if c == nil {
+		c = &http2Conn{ready: make(chan struct{})}
+		t.conns[addr] = c
+		t.locker.Unlock()
+		c.conn, c.err = t.dial(ctx, u)
+		close(c.ready)
+		if c.err != nil {
+			t.locker.Lock()
+			if t.conns[addr] == c {
+				delete(t.conns, addr)
+			}
+			t.locker.Unlock()
+		}
+		return c.conn, c.err
+	}
+	t.locker.Unlock()
+	select {
+	case <-c.ready:
+		return c.conn, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func isHTTP2ConnUsable(conn *http2.ClientConn) bool {
+	state := conn.State()
+	return !state.Closed && !state.Closing
+}
+
+// dial connects to u by the dialer and the tls.Config of the HTTP/1.1
+// transport, it returns nil if ALPN doesn't negotiate HTTP/2.
+func (t *http2Transport) dial(
+	ctx context.Context, u *url.URL) (*http2.ClientConn, error) {
+	dial := t.h1.DialContext
+	if dial == nil {
+		var dialer net.Dialer
+		dial = dialer.DialContext
+	}
+	conn, err := dial(ctx, "tcp", http2HostPort(u))
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "https" {
+		config := t.h1.TLSClientConfig
+		if config == nil {
+			config = new(tls.Config)
+		}
+		config = withNextProtos(config, "h2", "http/1.1")
+		if config.ServerName == "" {
+			config.ServerName = u.Hostname()
+		}
+		tlsConn := tls.Client(conn, config)
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if tlsConn.ConnectionState().NegotiatedProtocol != "h2" {
+			tlsConn.Close()
+			return nil, nil
+		}
+		conn = tlsConn
+	}
+	cc, err := t.h2.NewClientConn(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	// the SETTINGS of the server is received before the ack of the ping,
+	// so the requests never exceed its SETTINGS_MAX_CONCURRENT_STREAMS.
+	if err = cc.Ping(ctx); err != nil {
+		cc.Close()
+		return nil, err
+	}
+	return cc, nil
+}
+
+// closeConns shuts down the connections of the addresses after their
+// requests are finished, and forgets the addresses which don't support
+// HTTP/2, all the connections are shut down if addrs is nil.
+func (t *http2Transport) closeConns(addrs []string) {
+	var closed map[string]bool
+	if addrs != nil {
+		closed = make(map[string]bool, len(addrs))
+		for _, addr := range addrs {
+			closed[addr] = true
+		}
+	}
+	t.locker.Lock()
+	for addr, c := range t.conns {
+		if closed != nil && !closed[addr] {
+			continue
+		}
+		select {
+		case <-c.ready:
+			delete(t.conns, addr)
+			if c.conn != nil {
+				go c.conn.Shutdown(context.Background())
+			}
+		default:
+		}
+	}
+	t.locker.Unlock()
+}
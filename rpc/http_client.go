@@ -12,7 +12,7 @@
  *                                                        *
  * hprose http client for Go.                             *
  *                                                        *
- * LastModified: Oct 2, 2016                              *
+ * LastModified: Oct 18, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -20,6 +20,7 @@
 package rpc
 
 import (
+	"context"
 	"crypto/tls"
 	"io"
 	"io/ioutil"
@@ -40,9 +41,11 @@ var DisableGlobalCookie = false
 type HTTPClient struct {
 	BaseClient
 	limiter
+	compressor
 	http.Client
 	http.Transport
-	Header http.Header
+	Header      http.Header
+	h2Transport *http2Transport
 }
 
 // NewHTTPClient is the constructor of HTTPClient
@@ -50,6 +53,7 @@ func NewHTTPClient(uri ...string) (client *HTTPClient) {
 	client = new(HTTPClient)
 	client.initBaseClient()
 	client.initLimiter()
+	client.initCompressor()
 	client.Client.Transport = &client.Transport
 	client.DisableCompression = true
 	client.DisableKeepAlives = false
@@ -58,6 +62,8 @@ func NewHTTPClient(uri ...string) (client *HTTPClient) {
 	if DisableGlobalCookie {
 		client.Jar, _ = cookiejar.New(nil)
 	}
+	client.setURIList = client.SetURIList
+	client.closeURIs = client.closeIdleConns
 	client.SetURIList(uri)
 	client.SendAndReceive = client.sendAndReceive
 	return
@@ -96,6 +102,73 @@ func (client *HTTPClient) SetTLSClientConfig(config *tls.Config) {
 	client.Transport.TLSClientConfig = config
 }
 
+// HTTP2 returns whether HTTP/2 is enabled
+func (client *HTTPClient) HTTP2() bool {
+	return client.h2Transport != nil
+}
+
+// SetHTTP2 enables or disables HTTP/2. When it is enabled, every service
+// address is connected by one multiplexed connection, the https service
+// addresses negotiate HTTP/2 by ALPN and fall back to HTTP/1.1 if the server
+// doesn't support it, and the http service addresses are connected by
+// HTTP/2 cleartext (h2c) with prior knowledge, so the server must accept
+// h2c, for example, HTTPService with SetHTTP2(true).
+//
+// MaxConcurrentRequests limits the requests sent by HTTP/1.1 only, the
+// concurrent requests of an HTTP/2 connection are limited by the
+// SETTINGS_MAX_CONCURRENT_STREAMS of the server, and the requests exceeding
+// it wait for a free stream.
+//
+// The HTTP/2 connections are dialed by the DialContext and TLSClientConfig
+// of the embedded http.Transport.
+func (client *HTTPClient) SetHTTP2(enable bool) error {
+	if enable == client.HTTP2() {
+		return nil
+	}
+	if !enable {
+		client.h2Transport.closeConns(nil)
+		client.h2Transport = nil
+		client.Client.Transport = &client.Transport
+		return nil
+	}
+	client.h2Transport = newHTTP2Transport(&client.Transport)
+	client.Client.Transport = client.h2Transport
+	return nil
+}
+
+// withNextProtos returns a copy of config whose NextProtos starts with
+// protos, config is not modified because it may be shared.
+func withNextProtos(config *tls.Config, protos ...string) *tls.Config {
+	config = config.Clone()
+	config.NextProtos = append(protos,
+		subtractStringSlice(config.NextProtos, protos)...)
+	return config
+}
+
+// closeIdleConns closes the idle connections when the service addresses are
+// removed, http.Transport can't close the connections of a single address.
+func (client *HTTPClient) closeIdleConns(uriList []string) {
+	client.Transport.CloseIdleConnections()
+	if t := client.h2Transport; t != nil {
+		addrs := subtractStringSlice(
+			http2Addrs(uriList), http2Addrs(client.URIList()))
+		if len(addrs) > 0 {
+			t.closeConns(addrs)
+		}
+	}
+}
+
+// http2Addrs returns the http2Addr of the service addresses
+func http2Addrs(uriList []string) []string {
+	addrs := make([]string, 0, len(uriList))
+	for _, uri := range uriList {
+		if u, err := url.Parse(uri); err == nil {
+			addrs = append(addrs, http2Addr(u))
+		}
+	}
+	return addrs
+}
+
 // Timeout returns the client timeout setting
 func (client *HTTPClient) Timeout() time.Duration {
 	return client.BaseClient.timeout
@@ -116,11 +189,22 @@ func (client *HTTPClient) Compression() bool {
 	return !client.DisableCompression
 }
 
-// SetCompression set the compression status of hprose client
+// SetCompression set the compression status of hprose client, the requests
+// which are not smaller than CompressionMinSize are compressed by gzip, and
+// the responses are accepted in gzip or deflate.
 func (client *HTTPClient) SetCompression(enable bool) {
 	client.DisableCompression = !enable
 }
 
+// multiplexed returns true if the requests to uri are sent by HTTP/2
+func (client *HTTPClient) multiplexed(
+	ctx context.Context, uri string) (bool, error) {
+	if t := client.h2Transport; t != nil {
+		return t.multiplexed(ctx, uri)
+	}
+	return false, nil
+}
+
 func (client *HTTPClient) readAll(
 	response *http.Response) (data []byte, err error) {
 	if response.ContentLength > 0 {
@@ -136,13 +220,51 @@ func (client *HTTPClient) readAll(
 
 func (client *HTTPClient) sendAndReceive(
 	data []byte, context *ClientContext) ([]byte, error) {
-	client.cond.L.Lock()
-	client.limit()
-	client.cond.L.Unlock()
-	req, err := http.NewRequest("POST", client.uri, hio.NewByteReader(data))
+	ctx, cancel := withTimeout(context.Context(), context.Timeout)
+	defer cancel()
+	// the HTTP/2 requests are limited by the streams of the connection.
+	multiplexed, err := client.multiplexed(ctx, context.uri)
+	if err != nil {
+		if ctx.Err() != nil {
+			err = timeoutError(context.Context())
+		}
+		return nil, err
+	}
+	if !multiplexed {
+		client.cond.L.Lock()
+		err = client.limit(ctx)
+		client.cond.L.Unlock()
+		if err != nil {
+			return nil, timeoutError(context.Context())
+		}
+	}
+	data, err = client.doRequest(ctx, context.uri, data)
+	if !multiplexed {
+		client.cond.L.Lock()
+		client.unlimit()
+		client.cond.L.Unlock()
+	}
+	if err != nil && ctx.Err() != nil {
+		err = timeoutError(context.Context())
+	}
+	return data, err
+}
+
+func (client *HTTPClient) doRequest(
+	ctx context.Context, uri string, data []byte) ([]byte, error) {
+	compression := client.Compression()
+	compressed := compression && client.shouldCompress(data)
+	if compressed {
+		var err error
+		if data, err = client.compress(data, "gzip"); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequest("POST", uri, hio.NewByteReader(data))
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
 	for key, values := range client.Header {
 		for _, value := range values {
 			req.Header.Add(key, value)
@@ -150,17 +272,21 @@ func (client *HTTPClient) sendAndReceive(
 	}
 	req.ContentLength = int64(len(data))
 	req.Header.Set("Content-Type", "application/hprose")
-	client.Client.Timeout = context.Timeout
+	// the response is decompressed here instead of http.Transport, so that
+	// DecompressionLimit is enforced.
+	if compression {
+		req.Header.Set("Accept-Encoding", acceptedEncodings)
+	}
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
-	data, err = ioutil.ReadAll(resp.Body)
-	if err == nil {
-		err = resp.Body.Close()
+	data, err = client.decompress(resp.Body, resp.Header.Get("Content-Encoding"))
+	if e := resp.Body.Close(); err == nil {
+		err = e
 	}
-	client.cond.L.Lock()
-	client.unlimit()
-	client.cond.L.Unlock()
 	return data, err
 }
@@ -0,0 +1,185 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/http_client_test.go                                *
+ *                                                        *
+ * hprose http client test for Go.                        *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// concurrencyService records the max concurrent requests it serves.
+type concurrencyService struct {
+	sync.Mutex
+	active int
+	max    int
+}
+
+func (s *concurrencyService) proto(name string, request *http.Request) string {
+	s.Lock()
+	if s.active++; s.active > s.max {
+		s.max = s.active
+	}
+	s.Unlock()
+	time.Sleep(100 * time.Millisecond)
+	s.Lock()
+	s.active--
+	s.Unlock()
+	return request.Proto
+}
+
+func TestHTTP2ConcurrentRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		tls        bool
+		http2      bool
+		maxStreams uint32
+		proto      string
+		max        int
+	}{
+		{"h2c", false, true, 4, "HTTP/2.0", 4},
+		{"h2c", false, true, 16, "HTTP/2.0", 16},
+		{"h2", true, true, 4, "HTTP/2.0", 4},
+		{"http/1.1 fallback", true, false, 0, "HTTP/1.1", 2},
+	}
+	for _, test := range tests {
+		cs := &concurrencyService{}
+		service := NewHTTPService()
+		service.AddFunction("proto", cs.proto, Options{})
+		var server *httptest.Server
+		if test.tls {
+			server = httptest.NewUnstartedServer(service)
+			server.EnableHTTP2 = test.http2
+			server.Config.HTTP2 = &http.HTTP2Config{
+				MaxConcurrentStreams: int(test.maxStreams),
+			}
+			server.StartTLS()
+		} else {
+			service.h2cHandler = h2c.NewHandler(
+				http.HandlerFunc(service.serveHTTP),
+				&http2.Server{MaxConcurrentStreams: test.maxStreams})
+			server = httptest.NewServer(service)
+		}
+		client := NewHTTPClient(server.URL)
+		client.MaxConcurrentRequests = 2
+		if err := client.SetHTTP2(true); err != nil {
+			t.Fatal(err)
+		}
+		var wg sync.WaitGroup
+		for i := 0; i < 16; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				args := []reflect.Value{reflect.ValueOf("hprose")}
+				results, err := client.Invoke("proto", args, &InvokeSettings{
+					ResultTypes: []reflect.Type{stringType},
+				})
+				if err != nil {
+					t.Error(test.name, err)
+				} else if p := results[0].String(); p != test.proto {
+					t.Errorf("%s: proto is %s, want %s", test.name, p, test.proto)
+				}
+			}()
+		}
+		wg.Wait()
+		if cs.max != test.max {
+			t.Errorf("%s: max concurrent requests is %d, want %d",
+				test.name, cs.max, test.max)
+		}
+		client.Close()
+		server.Close()
+	}
+}
+
+func TestHTTPCompression(t *testing.T) {
+	large := strings.Repeat("hprose", 1000)
+	tests := []struct {
+		name           string
+		compression    bool
+		acceptEncoding string
+		arg            string
+		clientLimit    int64
+		serviceLimit   int64
+		request        string
+		response       string
+		err            string
+	}{
+		{"gzip", true, "", large, 0, 0, "gzip", "gzip", ""},
+		{"deflate", true, "deflate", large, 0, 0, "gzip", "deflate", ""},
+		{"small", true, "", "small", 0, 0, "", "", ""},
+		{"disabled", false, "", large, 0, 0, "", "", ""},
+		{"client limit", true, "", large, 1024, 0,
+			"gzip", "gzip", ErrContentTooLarge.Error()},
+		{"service limit", true, "", large, 0, 1024,
+			"gzip", "", ErrContentTooLarge.Error()},
+	}
+	for _, test := range tests {
+		service := NewHTTPService()
+		service.AddFunction("echo", func(s string) string { return s }, Options{})
+		if test.serviceLimit > 0 {
+			service.DecompressionLimit = test.serviceLimit
+		}
+		var request, response string
+		server := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				request = r.Header.Get("Content-Encoding")
+				if test.acceptEncoding != "" {
+					r.Header.Set("Accept-Encoding", test.acceptEncoding)
+				}
+				service.ServeHTTP(w, r)
+				response = w.Header().Get("Content-Encoding")
+			}))
+		client := NewHTTPClient(server.URL)
+		client.SetCompression(test.compression)
+		if test.clientLimit > 0 {
+			client.DecompressionLimit = test.clientLimit
+		}
+		args := []reflect.Value{reflect.ValueOf(test.arg)}
+		results, err := client.Invoke("echo", args, &InvokeSettings{
+			ResultTypes: []reflect.Type{stringType},
+		})
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%s: %v", test.name, err)
+			} else if results[0].String() != test.arg {
+				t.Errorf("%s: the echoed content is changed", test.name)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error %v, want %s", test.name, err, test.err)
+		}
+		if request != test.request {
+			t.Errorf("%s: request Content-Encoding is %q, want %q",
+				test.name, request, test.request)
+		}
+		if response != test.response {
+			t.Errorf("%s: response Content-Encoding is %q, want %q",
+				test.name, response, test.response)
+		}
+		client.Close()
+		server.Close()
+	}
+}
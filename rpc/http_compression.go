@@ -0,0 +1,168 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/http_compression.go                                *
+ *                                                        *
+ * hprose http compression for golang.                    *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// DefaultCompressionMinSize is the default CompressionMinSize, the smaller
+// content is sent without compression.
+const DefaultCompressionMinSize = 1024
+
+// DefaultDecompressionLimit is the default DecompressionLimit, 64 MiB.
+const DefaultDecompressionLimit = 64 << 20
+
+// acceptedEncodings is the Accept-Encoding header sent by the http clients
+const acceptedEncodings = "gzip, deflate"
+
+type compressor struct {
+	// CompressionLevel is the level of gzip and deflate compression.
+	CompressionLevel int
+	// CompressionMinSize is the minimum size of the compressed content.
+	CompressionMinSize int
+	// DecompressionLimit is the maximum size of the decompressed content,
+	// it guards against the zip bombs, 0 means no limit.
+	DecompressionLimit int64
+}
+
+func (c *compressor) initCompressor() {
+	c.CompressionLevel = gzip.DefaultCompression
+	c.CompressionMinSize = DefaultCompressionMinSize
+	c.DecompressionLimit = DefaultDecompressionLimit
+}
+
+// shouldCompress reports whether data is large enough to be compressed
+func (c *compressor) shouldCompress(data []byte) bool {
+	return len(data) > 0 && len(data) >= c.CompressionMinSize
+}
+
+// compress data by the content encoding, gzip or deflate
+func (c *compressor) compress(
+	data []byte, encoding string) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	var err error
+	switch encoding {
+	case "gzip":
+		w, err = gzip.NewWriterLevel(&buf, c.CompressionLevel)
+	case "deflate":
+		w, err = zlib.NewWriterLevel(&buf, c.CompressionLevel)
+	default:
+		err = errors.New("unsupported Content-Encoding: " + encoding)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(data); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress the content read from r by the content encoding,
+// ErrContentTooLarge is returned if the decompressed content exceeds
+// DecompressionLimit.
+func (c *compressor) decompress(
+	r io.Reader, encoding string) (data []byte, err error) {
+	var reader io.ReadCloser
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "identity":
+		return ioutil.ReadAll(r)
+	case "gzip", "x-gzip":
+		reader, err = gzip.NewReader(r)
+	case "deflate":
+		reader, err = newDeflateReader(r)
+	default:
+		err = errors.New("unsupported Content-Encoding: " + encoding)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	if c.DecompressionLimit <= 0 {
+		return ioutil.ReadAll(reader)
+	}
+	data, err = ioutil.ReadAll(io.LimitReader(reader, c.DecompressionLimit+1))
+	if err == nil && int64(len(data)) > c.DecompressionLimit {
+		return nil, ErrContentTooLarge
+	}
+	return data, err
+}
+
+// newDeflateReader accepts both the zlib format which is required by HTTP,
+// and the raw deflate format which is sent by some implementations.
+func newDeflateReader(r io.Reader) (io.ReadCloser, error) {
+	head := make([]byte, 2)
+	n, _ := io.ReadFull(r, head)
+	r = io.MultiReader(bytes.NewReader(head[:n]), r)
+	if n == 2 && head[0]&0x0f == 8 && (int(head[0])<<8|int(head[1]))%31 == 0 {
+		return zlib.NewReader(r)
+	}
+	return flate.NewReader(r), nil
+}
+
+// preferredEncoding returns the preferred content encoding in the
+// Accept-Encoding header, or an empty string if neither gzip nor deflate is
+// acceptable.
+func preferredEncoding(header string) string {
+	deflate := false
+	for _, item := range strings.Split(header, ",") {
+		params := strings.Split(item, ";")
+		encoding := strings.ToLower(strings.TrimSpace(params[0]))
+		if encoding != "gzip" && encoding != "deflate" {
+			continue
+		}
+		if rejected(params[1:]) {
+			continue
+		}
+		if encoding == "gzip" {
+			return encoding
+		}
+		deflate = true
+	}
+	if deflate {
+		return "deflate"
+	}
+	return ""
+}
+
+// rejected reports whether the quality value of params is 0
+func rejected(params []string) bool {
+	for _, param := range params {
+		param = strings.Replace(strings.TrimSpace(param), " ", "", -1)
+		if strings.HasPrefix(param, "q=") {
+			q, err := strconv.ParseFloat(param[2:], 64)
+			return err == nil && q == 0
+		}
+	}
+	return false
+}
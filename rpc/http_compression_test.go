@@ -0,0 +1,124 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/http_compression_test.go                           *
+ *                                                        *
+ * hprose http compression test for Go.                   *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"bytes"
+	"compress/flate"
+	"strings"
+	"testing"
+)
+
+func TestPreferredEncoding(t *testing.T) {
+	tests := []struct {
+		header   string
+		encoding string
+	}{
+		{"", ""},
+		{"gzip, deflate", "gzip"},
+		{"deflate, gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"GZIP", "gzip"},
+		{"br, identity", ""},
+		{"gzip;q=0, deflate", "deflate"},
+		{"gzip; q = 0, deflate;q=0.5", "deflate"},
+		{"gzip;q=0.0, deflate;q=0", ""},
+		{"gzip;q=1", "gzip"},
+	}
+	for _, test := range tests {
+		if encoding := preferredEncoding(test.header); encoding != test.encoding {
+			t.Errorf("preferredEncoding(%q) is %q, want %q",
+				test.header, encoding, test.encoding)
+		}
+	}
+}
+
+func TestCompressAndDecompress(t *testing.T) {
+	var c compressor
+	c.initCompressor()
+	data := []byte(strings.Repeat("hprose", 1000))
+	rawDeflate := new(bytes.Buffer)
+	w, _ := flate.NewWriter(rawDeflate, flate.DefaultCompression)
+	w.Write(data)
+	w.Close()
+	tests := []struct {
+		name       string
+		compress   string
+		decompress string
+		content    []byte
+	}{
+		{"gzip", "gzip", "gzip", nil},
+		{"x-gzip", "gzip", "x-gzip", nil},
+		{"deflate", "deflate", "Deflate", nil},
+		{"raw deflate", "", "deflate", rawDeflate.Bytes()},
+		{"identity", "", "identity", data},
+		{"no encoding", "", "", data},
+	}
+	for _, test := range tests {
+		content := test.content
+		if test.compress != "" {
+			var err error
+			if content, err = c.compress(data, test.compress); err != nil {
+				t.Fatalf("%s: %v", test.name, err)
+			}
+			if len(content) >= len(data) {
+				t.Errorf("%s: %d bytes are compressed to %d bytes",
+					test.name, len(data), len(content))
+			}
+		}
+		result, err := c.decompress(bytes.NewReader(content), test.decompress)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if !bytes.Equal(result, data) {
+			t.Errorf("%s: the decompressed content is changed", test.name)
+		}
+	}
+	if _, err := c.compress(data, "br"); err == nil {
+		t.Error("br is compressed")
+	}
+	if _, err := c.decompress(bytes.NewReader(data), "br"); err == nil {
+		t.Error("br is decompressed")
+	}
+}
+
+func TestDecompressionLimit(t *testing.T) {
+	var c compressor
+	c.initCompressor()
+	data := []byte(strings.Repeat("hprose", 100))
+	content, _ := c.compress(data, "gzip")
+	tests := []struct {
+		limit int64
+		err   error
+	}{
+		{int64(len(data)) - 1, ErrContentTooLarge},
+		{int64(len(data)), nil},
+		{0, nil},
+	}
+	for _, test := range tests {
+		c.DecompressionLimit = test.limit
+		result, err := c.decompress(bytes.NewReader(content), "gzip")
+		if err != test.err {
+			t.Errorf("limit %d: error %v, want %v", test.limit, err, test.err)
+		}
+		if err == nil && !bytes.Equal(result, data) {
+			t.Errorf("limit %d: the decompressed content is changed", test.limit)
+		}
+	}
+}
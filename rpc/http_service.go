@@ -12,7 +12,7 @@
  *                                                        *
  * hprose http service for Go.                            *
  *                                                        *
- * LastModified: Oct 6, 2016                              *
+ * LastModified: Oct 18, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -28,6 +28,8 @@ import (
 	"strings"
 
 	"github.com/hprose/hprose-golang/util"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 // HTTPContext is the hprose http context
@@ -50,6 +52,7 @@ func (context *HTTPContext) initHTTPContext(
 type HTTPService struct {
 	baseHTTPService
 	contextPool chan *HTTPContext
+	h2cHandler  http.Handler
 }
 
 type sendHeaderEvent interface {
@@ -201,8 +204,44 @@ func readAllFromHTTPRequest(request *http.Request) ([]byte, error) {
 	return nil, nil
 }
 
+// readRequest reads the request body, and decompresses it by the
+// Content-Encoding header.
+func (service *HTTPService) readRequest(request *http.Request) ([]byte, error) {
+	if encoding := request.Header.Get("Content-Encoding"); encoding != "" {
+		return service.decompress(request.Body, encoding)
+	}
+	return readAllFromHTTPRequest(request)
+}
+
+// HTTP2 returns whether the service accepts HTTP/2 cleartext (h2c)
+func (service *HTTPService) HTTP2() bool {
+	return service.h2cHandler != nil
+}
+
+// SetHTTP2 enables or disables HTTP/2 cleartext (h2c) before the service is
+// served, both the prior knowledge and the HTTP/1.1 Upgrade are accepted.
+// HTTP/2 over TLS is negotiated by ALPN of http.Server itself, it needs no
+// setting.
+func (service *HTTPService) SetHTTP2(enable bool) {
+	if !enable {
+		service.h2cHandler = nil
+	} else if service.h2cHandler == nil {
+		handler := http.HandlerFunc(service.serveHTTP)
+		service.h2cHandler = h2c.NewHandler(handler, &http2.Server{})
+	}
+}
+
 // ServeHTTP is the hprose http handler method
 func (service *HTTPService) ServeHTTP(
+	response http.ResponseWriter, request *http.Request) {
+	if handler := service.h2cHandler; handler != nil {
+		handler.ServeHTTP(response, request)
+		return
+	}
+	service.serveHTTP(response, request)
+}
+
+func (service *HTTPService) serveHTTP(
 	response http.ResponseWriter, request *http.Request) {
 	if service.clientAccessPolicyXMLHandler(response, request) ||
 		service.crossDomainXMLHandler(response, request) {
@@ -222,7 +261,7 @@ func (service *HTTPService) ServeHTTP(
 			}
 		case "POST":
 			var req []byte
-			if req, err = readAllFromHTTPRequest(request); err == nil {
+			if req, err = service.readRequest(request); err == nil {
 				resp = service.Handle(req, context)
 			}
 		}
@@ -231,6 +270,16 @@ func (service *HTTPService) ServeHTTP(
 		resp = service.endError(err, context)
 	}
 	service.releaseContext(context)
-	response.Header().Set("Content-Length", util.Itoa(len(resp)))
+	header := response.Header()
+	if service.Compression {
+		header.Add("Vary", "Accept-Encoding")
+		var encoding string
+		resp, encoding = service.compressResponse(
+			resp, request.Header.Get("Accept-Encoding"))
+		if encoding != "" {
+			header.Set("Content-Encoding", encoding)
+		}
+	}
+	header.Set("Content-Length", util.Itoa(len(resp)))
 	response.Write(resp)
 }
@@ -12,14 +12,17 @@
  *                                                        *
  * hprose client requests limiter for Go.                 *
  *                                                        *
- * LastModified: Oct 2, 2016                              *
+ * LastModified: Oct 18, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
 
 package rpc
 
-import "sync"
+import (
+	"context"
+	"sync"
+)
 
 type limiter struct {
 	cond                  sync.Cond
@@ -32,14 +35,15 @@ func (limiter *limiter) initLimiter() {
 	limiter.cond.L = &sync.Mutex{}
 }
 
-func (limiter *limiter) limit() {
-	for {
-		if limiter.requestCount < limiter.MaxConcurrentRequests {
-			break
-		}
-		limiter.cond.Wait()
+func (limiter *limiter) limit(ctx context.Context) error {
+	err := waitContext(ctx, &limiter.cond, func() bool {
+		return limiter.requestCount < limiter.MaxConcurrentRequests
+	})
+	if err != nil {
+		return err
 	}
 	limiter.requestCount++
+	return nil
 }
 
 func (limiter *limiter) unlimit() {
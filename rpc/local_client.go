@@ -0,0 +1,91 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/local_client.go                                    *
+ *                                                        *
+ * hprose in-process client for Go.                       *
+ *                                                        *
+ * LastModified: Oct 17, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import "sync"
+
+// localURI is the service address of LocalClient
+const localURI = "local://"
+
+type localService interface {
+	Service
+	Handle(request []byte, context Context) []byte
+}
+
+// LocalClient is hprose client which invokes the in-process hprose service
+// without sockets. The requests and the responses are still serialized, and
+// they pass through all of the filters and handlers of both sides, so it is
+// useful for testing the service and the push topics.
+type LocalClient struct {
+	BaseClient
+	service   localService
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewLocalClient is the constructor of LocalClient, service must be one of
+// the hprose services which are based on BaseService.
+func NewLocalClient(service Service) (client *LocalClient) {
+	s, ok := service.(localService)
+	if !ok {
+		panic("LocalClient: service must be based on BaseService")
+	}
+	client = new(LocalClient)
+	client.initBaseClient()
+	client.service = s
+	client.done = make(chan struct{})
+	client.SetURIList([]string{localURI})
+	client.SendAndReceive = client.sendAndReceive
+	return
+}
+
+// Close the client
+func (client *LocalClient) Close() {
+	client.BaseClient.Close()
+	client.closeOnce.Do(func() {
+		close(client.done)
+	})
+}
+
+func (client *LocalClient) sendAndReceive(
+	data []byte, context *ClientContext) ([]byte, error) {
+	select {
+	case <-client.done:
+		return nil, errClientIsAlreadyClosed
+	default:
+	}
+	ctx, cancel := withTimeout(context.Context(), context.Timeout)
+	defer cancel()
+	request := append([]byte(nil), data...)
+	response := make(chan []byte, 1)
+	go func() {
+		serviceContext := new(serviceContext)
+		serviceContext.initServiceContext(client.service)
+		response <- client.service.Handle(request, serviceContext)
+	}()
+	select {
+	case data := <-response:
+		return data, nil
+	case <-client.done:
+		return nil, errClientIsAlreadyClosed
+	case <-ctx.Done():
+		return nil, timeoutError(context.Context())
+	}
+}
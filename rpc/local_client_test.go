@@ -0,0 +1,110 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/local_client_test.go                               *
+ *                                                        *
+ * hprose local client test for Go.                       *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type localStub struct {
+	Hello func(string) (string, error)
+	Fail  func() error
+	Slow  func() error `timeout:"20000000"`
+}
+
+func TestLocalClient(t *testing.T) {
+	service := NewTCPService()
+	service.AddFunction("hello", func(name string) string {
+		return "hello " + name
+	}, Options{})
+	service.AddFunction("fail", func() error {
+		return errors.New("failed")
+	}, Options{})
+	service.AddFunction("slow", func() {
+		time.Sleep(200 * time.Millisecond)
+	}, Options{})
+	client := NewLocalClient(service)
+	var stub *localStub
+	client.UseService(&stub)
+	cases := []struct {
+		name string
+		call func() error
+		err  string
+	}{
+		{"invoke", func() error {
+			result, err := stub.Hello("world")
+			if err == nil && result != "hello world" {
+				t.Errorf("invoke: result is %q", result)
+			}
+			return err
+		}, ""},
+		{"server error", stub.Fail, "failed"},
+		{"timeout", stub.Slow, ErrTimeout.Error()},
+		{"closed", func() error {
+			client.Close()
+			_, err := stub.Hello("world")
+			return err
+		}, errClientIsAlreadyClosed.Error()},
+	}
+	for _, c := range cases {
+		err := c.call()
+		if c.err == "" && err != nil || c.err != "" &&
+			(err == nil || err.Error() != c.err) {
+			t.Errorf("%s: error %v, want %q", c.name, err, c.err)
+		}
+	}
+}
+
+func TestLocalClientService(t *testing.T) {
+	defer func() {
+		if e := recover(); e == nil {
+			t.Error("the service which is not based on BaseService is accepted")
+		}
+	}()
+	NewLocalClient(struct{ Service }{})
+}
+
+func TestLocalClientPush(t *testing.T) {
+	service := NewTCPService()
+	service.Publish("news", 0, 0)
+	client := NewLocalClient(service)
+	defer client.Close()
+	received := make(chan string, 1)
+	if err := client.Subscribe("news", "", func(news string) {
+		received <- news
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+	id, _ := client.getID()
+	for i := 0; i < 200 && !service.Exist("news", id); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	service.Push("news", "breaking")
+	select {
+	case news := <-received:
+		if news != "breaking" {
+			t.Errorf("receive %q, want breaking", news)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the pushed message is not received")
+	}
+}
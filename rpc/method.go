@@ -12,7 +12,7 @@
  *                                                        *
  * hprose method manager for Go.                          *
  *                                                        *
- * LastModified: Oct 7, 2016                              *
+ * LastModified: Oct 17, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -23,6 +23,8 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+
+	"github.com/hprose/hprose-golang/io"
 )
 
 // Options is the options of the published service method
@@ -34,9 +36,21 @@ type Options struct {
 	JSONCompatible bool
 }
 
+// Invoker invokes the published service method without reflection,
+// it is usually generated by hprose-gen.
+type Invoker interface {
+	// ReadArguments reads count arguments of the method from the reader,
+	// the returned arguments should include the parameters which are not in
+	// the request.
+	ReadArguments(reader *io.Reader, count int) []reflect.Value
+	// Invoke calls the method with the arguments
+	Invoke(args []reflect.Value) ([]reflect.Value, error)
+}
+
 // Method is the published service method
 type Method struct {
 	Function reflect.Value
+	Invoker  Invoker
 	Options
 }
 
@@ -71,12 +85,31 @@ func (mm *methodManager) AddFunction(
 	if f.Kind() != reflect.Func {
 		panic("function must be func or bound method")
 	}
-	if options.NameSpace != "" && name != "*" {
-		name = options.NameSpace + "_" + name
+	mm.addMethod(name, &Method{Function: f, Options: options})
+}
+
+// AddInvoker publish a method which is invoked by the invoker
+// name is the method name
+// invoker reads the arguments and calls the method without reflection
+// options includes Mode, Simple, Oneway and NameSpace
+func (mm *methodManager) AddInvoker(
+	name string, invoker Invoker, options Options) {
+	if name == "" {
+		panic("name can't be empty")
+	}
+	if invoker == nil {
+		panic("invoker can't be nil")
+	}
+	mm.addMethod(name, &Method{Invoker: invoker, Options: options})
+}
+
+func (mm *methodManager) addMethod(name string, method *Method) {
+	if method.NameSpace != "" && name != "*" {
+		name = method.NameSpace + "_" + name
 	}
 	mm.Lock()
 	mm.MethodNames = append(mm.MethodNames, name)
-	mm.RemoteMethods[strings.ToLower(name)] = &Method{f, options}
+	mm.RemoteMethods[strings.ToLower(name)] = method
 	mm.Unlock()
 }
 
@@ -289,7 +322,7 @@ func (mm *methodManager) AddMissingMethod(
 //		var stub *Stub
 //		client.UseService(&stub)
 //		fmt.Println(stub.Multiply(&Args{8, 7}))
-//		quo, e := stub.Device(&Args{8, 7})
+//		quo, e := stub.Divide(&Args{8, 7})
 //		if err := <-e; err != nil {
 //			log.Fatal("arith error:", err)
 //		}
@@ -0,0 +1,279 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/metrics.go                                         *
+ *                                                        *
+ * hprose client metrics for Go.                          *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MetricsCollector collects the metrics of hprose client.
+//
+// The method is the remote method name, it is empty for the batch request
+// and the function list request. The uri is the service address which the
+// request is sent to.
+type MetricsCollector interface {
+	// Invoked is called when an invocation is done, elapsed includes the time
+	// spent on the retries.
+	Invoked(method string, uri string, elapsed time.Duration, err error)
+	// Transferred is called when a request is sent to uri, sent and received
+	// are the sizes of the request and the response before the filters.
+	Transferred(method string, uri string, sent int, received int, err error)
+	// Retried is called when a failed request is going to be retried.
+	Retried(method string, uri string)
+	// Failswitched is called when the client switches the service address.
+	Failswitched(from string, to string)
+}
+
+// DefaultLatencyBuckets are the default upper bounds of the latency histogram
+var DefaultLatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// LatencyHistogram is the latency histogram of the invocations
+type LatencyHistogram struct {
+	// Buckets are the sorted upper bounds of the histogram
+	Buckets []time.Duration
+	// Counts[i] is the number of the latencies which are less than or equal
+	// to Buckets[i] and greater than Buckets[i-1], the last one counts the
+	// latencies which are greater than all the Buckets.
+	Counts []int64
+	Count  int64
+	Sum    time.Duration
+	Max    time.Duration
+}
+
+func newLatencyHistogram(buckets []time.Duration) *LatencyHistogram {
+	return &LatencyHistogram{
+		Buckets: buckets,
+		Counts:  make([]int64, len(buckets)+1),
+	}
+}
+
+func (h *LatencyHistogram) observe(latency time.Duration) {
+	i := sort.Search(len(h.Buckets), func(i int) bool {
+		return latency <= h.Buckets[i]
+	})
+	h.Counts[i]++
+	h.Count++
+	h.Sum += latency
+	if latency > h.Max {
+		h.Max = latency
+	}
+}
+
+// Mean returns the mean latency
+func (h *LatencyHistogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// Quantile returns the upper bound of the bucket which the q-quantile latency
+// falls into, it returns Max if the q-quantile latency is greater than all the
+// Buckets.
+func (h *LatencyHistogram) Quantile(q float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	rank := int64(q*float64(h.Count) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+	var n int64
+	for i, count := range h.Counts[:len(h.Buckets)] {
+		if n += count; n >= rank {
+			return h.Buckets[i]
+		}
+	}
+	return h.Max
+}
+
+func (h *LatencyHistogram) clone() *LatencyHistogram {
+	c := *h
+	c.Counts = append([]int64(nil), h.Counts...)
+	return &c
+}
+
+// Metrics are the client metrics of a method or a service address
+type Metrics struct {
+	Calls         int64
+	Errors        int64
+	Latency       *LatencyHistogram
+	Requests      int64
+	BytesSent     int64
+	BytesReceived int64
+	Timeouts      int64
+	Retries       int64
+	Failswitches  int64
+}
+
+func newMetrics(buckets []time.Duration) *Metrics {
+	return &Metrics{Latency: newLatencyHistogram(buckets)}
+}
+
+func (m *Metrics) clone() *Metrics {
+	c := *m
+	c.Latency = m.Latency.clone()
+	return &c
+}
+
+// MetricsSnapshot is the snapshot of the MemoryMetrics
+type MetricsSnapshot struct {
+	Methods map[string]*Metrics
+	URIs    map[string]*Metrics
+}
+
+// MemoryMetrics is an in-memory MetricsCollector which records the metrics per
+// method and per service address.
+type MemoryMetrics struct {
+	buckets []time.Duration
+	methods map[string]*Metrics
+	uris    map[string]*Metrics
+	locker  sync.Mutex
+}
+
+// NewMemoryMetrics is the constructor of MemoryMetrics, buckets are the upper
+// bounds of the latency histogram, DefaultLatencyBuckets are used if they are
+// omitted.
+func NewMemoryMetrics(buckets ...time.Duration) *MemoryMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]time.Duration(nil), buckets...)
+	sort.Sort(durationSlice(buckets))
+	m := &MemoryMetrics{buckets: buckets}
+	m.Reset()
+	return m
+}
+
+func (m *MemoryMetrics) get(metrics map[string]*Metrics, key string) *Metrics {
+	if value, ok := metrics[key]; ok {
+		return value
+	}
+	value := newMetrics(m.buckets)
+	metrics[key] = value
+	return value
+}
+
+// targets returns the metrics of the method and the service address,
+// the empty method or uri is not recorded.
+func (m *MemoryMetrics) targets(method string, uri string) []*Metrics {
+	targets := make([]*Metrics, 0, 2)
+	if method != "" {
+		targets = append(targets, m.get(m.methods, method))
+	}
+	if uri != "" {
+		targets = append(targets, m.get(m.uris, uri))
+	}
+	return targets
+}
+
+// Invoked records the call count, error count and latency of the invocation
+func (m *MemoryMetrics) Invoked(
+	method string, uri string, elapsed time.Duration, err error) {
+	m.locker.Lock()
+	for _, metrics := range m.targets(method, uri) {
+		metrics.Calls++
+		if err != nil {
+			metrics.Errors++
+		}
+		metrics.Latency.observe(elapsed)
+	}
+	m.locker.Unlock()
+}
+
+// Transferred records the request count, bytes and timeouts of the request
+func (m *MemoryMetrics) Transferred(
+	method string, uri string, sent int, received int, err error) {
+	m.locker.Lock()
+	for _, metrics := range m.targets(method, uri) {
+		metrics.Requests++
+		metrics.BytesSent += int64(sent)
+		metrics.BytesReceived += int64(received)
+		if err == ErrTimeout || err == context.DeadlineExceeded {
+			metrics.Timeouts++
+		}
+	}
+	m.locker.Unlock()
+}
+
+// Retried records the retry of the request
+func (m *MemoryMetrics) Retried(method string, uri string) {
+	m.locker.Lock()
+	for _, metrics := range m.targets(method, uri) {
+		metrics.Retries++
+	}
+	m.locker.Unlock()
+}
+
+// Failswitched records the failswitch from the service address
+func (m *MemoryMetrics) Failswitched(from string, to string) {
+	m.locker.Lock()
+	for _, metrics := range m.targets("", from) {
+		metrics.Failswitches++
+	}
+	m.locker.Unlock()
+}
+
+// Snapshot returns a copy of the current metrics
+func (m *MemoryMetrics) Snapshot() *MetricsSnapshot {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+	snapshot := &MetricsSnapshot{
+		Methods: make(map[string]*Metrics, len(m.methods)),
+		URIs:    make(map[string]*Metrics, len(m.uris)),
+	}
+	for key, value := range m.methods {
+		snapshot.Methods[key] = value.clone()
+	}
+	for key, value := range m.uris {
+		snapshot.URIs[key] = value.clone()
+	}
+	return snapshot
+}
+
+// Reset clears all the metrics
+func (m *MemoryMetrics) Reset() {
+	m.locker.Lock()
+	m.methods = make(map[string]*Metrics)
+	m.uris = make(map[string]*Metrics)
+	m.locker.Unlock()
+}
+
+type durationSlice []time.Duration
+
+func (s durationSlice) Len() int           { return len(s) }
+func (s durationSlice) Less(i, j int) bool { return s[i] < s[j] }
+func (s durationSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
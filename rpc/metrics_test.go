@@ -0,0 +1,74 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/metrics_test.go                                    *
+ *                                                        *
+ * hprose metrics test for Go.                            *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryMetricsTimeouts(t *testing.T) {
+	tests := []struct {
+		err      error
+		timeouts int64
+	}{
+		{nil, 0},
+		{errors.New("error"), 0},
+		{context.Canceled, 0},
+		{ErrTimeout, 1},
+		{context.DeadlineExceeded, 1},
+	}
+	for _, test := range tests {
+		metrics := NewMemoryMetrics()
+		metrics.Transferred("hello", "tcp://127.0.0.1:1", 10, 0, test.err)
+		snapshot := metrics.Snapshot()
+		if n := snapshot.Methods["hello"].Timeouts; n != test.timeouts {
+			t.Errorf("%v: timeouts are %d, want %d", test.err, n, test.timeouts)
+		}
+		if n := snapshot.URIs["tcp://127.0.0.1:1"].Requests; n != 1 {
+			t.Errorf("%v: requests are %d, want 1", test.err, n)
+		}
+	}
+}
+
+func TestMemoryMetricsContextDeadline(t *testing.T) {
+	server := NewTCPServer("")
+	server.AddFunction("sleep", func() {
+		time.Sleep(200 * time.Millisecond)
+	}, Options{})
+	server.Handle()
+	defer server.Close()
+	client := NewTCPClient(server.URI())
+	defer client.Close()
+	client.SetRetry(0)
+	metrics := NewMemoryMetrics()
+	client.SetMetrics(metrics)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.InvokeContext(ctx, "sleep", nil, &InvokeSettings{})
+	if err != context.DeadlineExceeded {
+		t.Fatalf("err is %v, want %v", err, context.DeadlineExceeded)
+	}
+	if n := metrics.Snapshot().URIs[server.URI()].Timeouts; n != 1 {
+		t.Fatalf("timeouts are %d, want 1", n)
+	}
+}
@@ -0,0 +1,167 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/rate_limiter.go                                    *
+ *                                                        *
+ * hprose client rate limiter for Go.                     *
+ *                                                        *
+ * LastModified: Oct 17, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter limits the request rate of hprose client
+type RateLimiter interface {
+	// Allow takes a permit and returns true if a request can be sent now,
+	// otherwise returns false immediately.
+	Allow() bool
+	// Wait blocks until a permit is taken or ctx is done.
+	Wait(ctx context.Context) error
+}
+
+// RateLimitError represents the request is rejected by the rate limiter
+type RateLimitError struct {
+	Method string
+}
+
+// Error implements the RateLimitError Error method.
+func (e *RateLimitError) Error() string {
+	if e.Method == "" {
+		return "rate limit exceeded"
+	}
+	return "rate limit exceeded: " + e.Method
+}
+
+// waitRateLimiter calls reserve until it returns a zero delay or ctx is done
+func waitRateLimiter(
+	ctx context.Context, reserve func() time.Duration) error {
+	for {
+		delay := reserve()
+		if delay <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// TokenBucket is a RateLimiter which allows rate requests per second on
+// average, and bursts of at most burst requests.
+type TokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	locker sync.Mutex
+}
+
+// NewTokenBucket is the constructor of TokenBucket
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if rate <= 0 {
+		panic("rate must be positive")
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token and returns 0 if there is one,
+// otherwise returns the delay until the next token is available.
+func (bucket *TokenBucket) reserve() time.Duration {
+	bucket.locker.Lock()
+	defer bucket.locker.Unlock()
+	now := time.Now()
+	bucket.tokens += now.Sub(bucket.last).Seconds() * bucket.rate
+	if bucket.tokens > bucket.burst {
+		bucket.tokens = bucket.burst
+	}
+	bucket.last = now
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return 0
+	}
+	return time.Duration((1 - bucket.tokens) / bucket.rate * float64(time.Second))
+}
+
+// Allow takes a token and returns true if there is one
+func (bucket *TokenBucket) Allow() bool {
+	return bucket.reserve() <= 0
+}
+
+// Wait blocks until a token is taken or ctx is done
+func (bucket *TokenBucket) Wait(ctx context.Context) error {
+	return waitRateLimiter(ctx, bucket.reserve)
+}
+
+// SlidingWindow is a RateLimiter which allows at most limit requests in any
+// window duration.
+type SlidingWindow struct {
+	window time.Duration
+	times  []time.Time
+	next   int
+	locker sync.Mutex
+}
+
+// NewSlidingWindow is the constructor of SlidingWindow
+func NewSlidingWindow(limit int, window time.Duration) *SlidingWindow {
+	if limit <= 0 || window <= 0 {
+		panic("limit and window must be positive")
+	}
+	return &SlidingWindow{
+		window: window,
+		times:  make([]time.Time, limit),
+	}
+}
+
+// reserve records the request and returns 0 if there are less than limit
+// requests in the window, otherwise returns the delay until the oldest
+// request leaves the window.
+func (sw *SlidingWindow) reserve() time.Duration {
+	sw.locker.Lock()
+	defer sw.locker.Unlock()
+	now := time.Now()
+	// times is a ring buffer, the next one is the oldest request.
+	if delay := sw.times[sw.next].Add(sw.window).Sub(now); delay > 0 {
+		return delay
+	}
+	sw.times[sw.next] = now
+	sw.next = (sw.next + 1) % len(sw.times)
+	return 0
+}
+
+// Allow records the request and returns true if there are less than limit
+// requests in the window
+func (sw *SlidingWindow) Allow() bool {
+	return sw.reserve() <= 0
+}
+
+// Wait blocks until the request can be sent or ctx is done
+func (sw *SlidingWindow) Wait(ctx context.Context) error {
+	return waitRateLimiter(ctx, sw.reserve)
+}
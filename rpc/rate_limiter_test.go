@@ -0,0 +1,114 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/rate_limiter_test.go                               *
+ *                                                        *
+ * hprose rate limiter test for Go.                       *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	bucket := NewTokenBucket(10, 3)
+	for i := 0; i < 3; i++ {
+		if !bucket.Allow() {
+			t.Fatalf("burst request %d is rejected", i)
+		}
+	}
+	if bucket.Allow() {
+		t.Fatal("the request exceeding the burst is allowed")
+	}
+	time.Sleep(120 * time.Millisecond)
+	if !bucket.Allow() {
+		t.Fatal("the refilled token is not allowed")
+	}
+	if bucket.Allow() {
+		t.Fatal("more than one token is refilled")
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	sw := NewSlidingWindow(2, 100*time.Millisecond)
+	if !sw.Allow() || !sw.Allow() {
+		t.Fatal("the requests within the limit are rejected")
+	}
+	if sw.Allow() {
+		t.Fatal("the request exceeding the limit is allowed")
+	}
+	time.Sleep(110 * time.Millisecond)
+	if !sw.Allow() || !sw.Allow() {
+		t.Fatal("the requests in the next window are rejected")
+	}
+	if sw.Allow() {
+		t.Fatal("the request exceeding the limit is allowed")
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	tests := []struct {
+		name    string
+		limiter RateLimiter
+		delay   time.Duration
+	}{
+		{"TokenBucket", NewTokenBucket(20, 1), 50 * time.Millisecond},
+		{"SlidingWindow", NewSlidingWindow(1, 50*time.Millisecond), 50 * time.Millisecond},
+	}
+	for _, test := range tests {
+		if err := test.limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		start := time.Now()
+		if err := test.limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if d := time.Since(start); d < test.delay*4/5 || d > test.delay*4 {
+			t.Errorf("%s waits %v, want about %v", test.name, d, test.delay)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), test.delay/5)
+		err := test.limiter.Wait(ctx)
+		cancel()
+		if err != context.DeadlineExceeded {
+			t.Errorf("%s: Wait returns %v, want %v",
+				test.name, err, context.DeadlineExceeded)
+		}
+	}
+}
+
+func TestRateLimiterPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		new  func()
+	}{
+		{"NewTokenBucket(0, 1)", func() { NewTokenBucket(0, 1) }},
+		{"NewTokenBucket(-1, 1)", func() { NewTokenBucket(-1, 1) }},
+		{"NewSlidingWindow(0, time.Second)", func() { NewSlidingWindow(0, time.Second) }},
+		{"NewSlidingWindow(1, 0)", func() { NewSlidingWindow(1, 0) }},
+	}
+	for _, test := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s doesn't panic", test.name)
+				}
+			}()
+			test.new()
+		}()
+	}
+}
@@ -0,0 +1,325 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/recorder.go                                        *
+ *                                                        *
+ * hprose record/replay transport for golang.             *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	hio "github.com/hprose/hprose-golang/io"
+)
+
+// Recording is a request and response pair captured by Recorder. Method is
+// the method name parsed from the request, the names of a batch request are
+// separated by comma, and it is empty for the function list request.
+// ErrorKind is the type of the error which is rebuilt by Replayer, it is
+// "dial" for DialError, and URI is the service address of the DialError.
+type Recording struct {
+	Method    string `json:"method"`
+	Request   []byte `json:"request"`
+	Response  []byte `json:"response,omitempty"`
+	Error     string `json:"error,omitempty"`
+	ErrorKind string `json:"errorKind,omitempty"`
+	URI       string `json:"uri,omitempty"`
+}
+
+const dialErrorKind = "dial"
+
+// recordedErrors are the errors which are restored by Replayer with their
+// original values, the other errors are replayed as the errors with the
+// same message.
+var recordedErrors = []error{
+	ErrTimeout,
+	ErrCircuitOpen,
+	ErrNoServiceAddress,
+	ErrContentTooLarge,
+	ErrTooManyQueuedCalls,
+	ErrHeartbeatTimeout,
+}
+
+func (recording *Recording) setErr(err error) {
+	recording.Error = err.Error()
+	if e, ok := err.(*DialError); ok {
+		recording.ErrorKind = dialErrorKind
+		recording.URI = e.URI
+	}
+}
+
+func (recording *Recording) err() error {
+	if recording.Error == "" {
+		return nil
+	}
+	if recording.ErrorKind == dialErrorKind {
+		prefix := "dial " + recording.URI + ": "
+		if strings.HasPrefix(recording.Error, prefix) {
+			return &DialError{
+				recording.URI, recordedError(recording.Error[len(prefix):]),
+			}
+		}
+	}
+	return recordedError(recording.Error)
+}
+
+func recordedError(message string) error {
+	for _, err := range recordedErrors {
+		if err.Error() == message {
+			return err
+		}
+	}
+	return errors.New(message)
+}
+
+// Recorder wraps the SendAndReceive of a client, and writes every request
+// and response pair to the fixture as a line of JSON.
+type Recorder struct {
+	client  *BaseClient
+	send    func([]byte, *ClientContext) ([]byte, error)
+	writer  io.Writer
+	encoder *json.Encoder
+	locker  sync.Mutex
+	err     error
+}
+
+// NewRecorder starts to record the requests and the responses of client to
+// w. The client must be one of the hprose clients which are based on
+// BaseClient.
+func NewRecorder(client Client, w io.Writer) (recorder *Recorder) {
+	recorder = new(Recorder)
+	recorder.client = baseClientOf(client, "Recorder")
+	recorder.send = recorder.client.SendAndReceive
+	recorder.writer = w
+	recorder.encoder = json.NewEncoder(w)
+	recorder.client.SendAndReceive = recorder.sendAndReceive
+	return
+}
+
+// NewFileRecorder starts to record the requests and the responses of client
+// to the fixture file filename, the file is truncated if it already exists.
+func NewFileRecorder(
+	client Client, filename string) (recorder *Recorder, err error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	return NewRecorder(client, file), nil
+}
+
+// Err returns the first error of writing the fixture.
+func (recorder *Recorder) Err() error {
+	recorder.locker.Lock()
+	defer recorder.locker.Unlock()
+	return recorder.err
+}
+
+// Close stops recording and restores the SendAndReceive of the client, the
+// fixture file is closed if the recorder is created by NewFileRecorder.
+func (recorder *Recorder) Close() error {
+	recorder.locker.Lock()
+	defer recorder.locker.Unlock()
+	if recorder.encoder == nil {
+		return recorder.err
+	}
+	recorder.client.SendAndReceive = recorder.send
+	recorder.encoder = nil
+	if file, ok := recorder.writer.(*os.File); ok {
+		if err := file.Close(); err != nil && recorder.err == nil {
+			recorder.err = err
+		}
+	}
+	return recorder.err
+}
+
+func (recorder *Recorder) sendAndReceive(
+	request []byte, context *ClientContext) (response []byte, err error) {
+	response, err = recorder.send(request, context)
+	recording := &Recording{
+		Method:   requestMethod(request, context),
+		Request:  request,
+		Response: response,
+	}
+	if err != nil {
+		recording.setErr(err)
+	}
+	recorder.locker.Lock()
+	if recorder.encoder != nil {
+		if e := recorder.encoder.Encode(recording); e != nil && recorder.err == nil {
+			recorder.err = e
+		}
+	}
+	recorder.locker.Unlock()
+	return
+}
+
+// RecordingMatcher reports whether the recording is the reply of request.
+type RecordingMatcher func(request []byte, recording *Recording) bool
+
+// MatchExact matches the recordings whose request bytes equal to request.
+func MatchExact(request []byte, recording *Recording) bool {
+	return bytes.Equal(request, recording.Request)
+}
+
+// MatchMethod matches the recordings of the same method as request,
+// regardless of the arguments.
+func MatchMethod(request []byte, recording *Recording) bool {
+	return requestMethod(request, nil) == recording.Method
+}
+
+// ReplayError represents no recording matches the request
+type ReplayError struct {
+	Method string
+}
+
+// Error implements the ReplayError Error method.
+func (e *ReplayError) Error() string {
+	if e.Method == "" {
+		return "no recording matches the request"
+	}
+	return "no recording matches the request: " + e.Method
+}
+
+// Replayer serves the responses captured by Recorder without a server.
+// The recordings matching a request are replayed in the recorded order,
+// and the last one is repeated after all of them are replayed.
+type Replayer struct {
+	Matcher    RecordingMatcher
+	recordings []*Recording
+	replayed   []bool
+	locker     sync.Mutex
+}
+
+// NewReplayer reads the recordings from r, Matcher defaults to MatchExact
+// when matcher is nil.
+func NewReplayer(
+	r io.Reader, matcher RecordingMatcher) (replayer *Replayer, err error) {
+	replayer = new(Replayer)
+	if matcher == nil {
+		matcher = MatchExact
+	}
+	replayer.Matcher = matcher
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	for {
+		recording := new(Recording)
+		if err = decoder.Decode(recording); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		replayer.recordings = append(replayer.recordings, recording)
+	}
+	replayer.replayed = make([]bool, len(replayer.recordings))
+	return replayer, nil
+}
+
+// NewFileReplayer reads the recordings from the fixture file filename.
+func NewFileReplayer(
+	filename string, matcher RecordingMatcher) (*Replayer, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return NewReplayer(file, matcher)
+}
+
+// Recordings returns the recordings of the replayer.
+func (replayer *Replayer) Recordings() []*Recording {
+	return replayer.recordings
+}
+
+// Replay replaces the SendAndReceive of client with the replayer, the
+// client must be one of the hprose clients which are based on BaseClient.
+// The service address of client is never connected.
+func (replayer *Replayer) Replay(client Client) {
+	baseClientOf(client, "Replayer").SendAndReceive = replayer.SendAndReceive
+}
+
+// SendAndReceive returns the recorded response of request.
+func (replayer *Replayer) SendAndReceive(
+	request []byte, context *ClientContext) ([]byte, error) {
+	replayer.locker.Lock()
+	defer replayer.locker.Unlock()
+	last := -1
+	for i, recording := range replayer.recordings {
+		if !replayer.Matcher(request, recording) {
+			continue
+		}
+		if !replayer.replayed[i] {
+			last = i
+			break
+		}
+		last = i
+	}
+	if last < 0 {
+		return nil, &ReplayError{requestMethod(request, context)}
+	}
+	replayer.replayed[last] = true
+	recording := replayer.recordings[last]
+	return append([]byte(nil), recording.Response...), recording.err()
+}
+
+type baseClientGetter interface {
+	baseClient() *BaseClient
+}
+
+func baseClientOf(client Client, name string) *BaseClient {
+	if c, ok := client.(baseClientGetter); ok {
+		return c.baseClient()
+	}
+	panic(name + ": client must be based on BaseClient")
+}
+
+// requestMethod parses the method names from the hprose request, the
+// method of context is returned if request is not a plain hprose request,
+// for example, it is compressed by a filter.
+func requestMethod(request []byte, context *ClientContext) (method string) {
+	defer func() {
+		if e := recover(); e != nil {
+			method = ""
+		}
+		if method == "" && context != nil {
+			method = context.method
+		}
+	}()
+	var names []string
+	reader := hio.NewRawReader(request)
+	tag, _ := reader.ReadByte()
+	for tag == hio.TagCall {
+		name := hio.NewReader(reader.ReadRaw(), false).ReadString()
+		names = append(names, name)
+		tag, _ = reader.ReadByte()
+		if tag == hio.TagList {
+			reader.UnreadByte()
+			reader.ReadRaw()
+			if tag, _ = reader.ReadByte(); tag == hio.TagTrue {
+				tag, _ = reader.ReadByte()
+			}
+		}
+	}
+	if tag != hio.TagEnd {
+		return ""
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,178 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/recorder_test.go                                   *
+ *                                                        *
+ * hprose recorder test for Go.                           *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRecordingError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+	}{
+		{"sentinel", ErrCircuitOpen},
+		{"plain", errors.New("connection reset")},
+		{"dial", &DialError{"tcp://127.0.0.1:1", errors.New("refused")}},
+		{"dial timeout", &DialError{"tcp://127.0.0.1:1", ErrTimeout}},
+	}
+	for _, c := range cases {
+		recording := new(Recording)
+		recording.setErr(c.err)
+		data, err := json.Marshal(recording)
+		if err != nil {
+			t.Fatal(err)
+		}
+		replayed := new(Recording)
+		if err = json.Unmarshal(data, replayed); err != nil {
+			t.Fatal(err)
+		}
+		err = replayed.err()
+		if reflect.TypeOf(err) != reflect.TypeOf(c.err) ||
+			err.Error() != c.err.Error() {
+			t.Errorf("%s: replay %#v, want %#v", c.name, err, c.err)
+		}
+		if c.err == ErrCircuitOpen && err != ErrCircuitOpen {
+			t.Errorf("%s: replay %v, want the sentinel", c.name, err)
+		}
+		if e, ok := c.err.(*DialError); ok && e.Err == ErrTimeout {
+			if err.(*DialError).Err != ErrTimeout {
+				t.Errorf("%s: replay %v, want ErrTimeout", c.name, err)
+			}
+		}
+	}
+}
+
+func TestReplayDialError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := "tcp://" + listener.Addr().String()
+	listener.Close()
+	client := NewTCPClient(uri)
+	client.SetRetry(0)
+	defer client.Close()
+	fixture := new(bytes.Buffer)
+	recorder := NewRecorder(client, fixture)
+	args := []reflect.Value{reflect.ValueOf("world")}
+	_, recorded := client.Invoke("hello", args, nil)
+	recorder.Close()
+	if _, ok := recorded.(*DialError); !ok {
+		t.Fatalf("record %#v, want DialError", recorded)
+	}
+	replayer, err := NewReplayer(fixture, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayer.Replay(client)
+	_, err = client.Invoke("hello", args, nil)
+	e, ok := err.(*DialError)
+	if !ok || e.URI != uri || e.Error() != recorded.Error() {
+		t.Errorf("replay %#v, want %#v", err, recorded)
+	}
+}
+
+type recorderStub struct {
+	Hello func(string) (string, error)
+	Fail  func() error
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	server := NewTCPServer("")
+	server.AddFunction("hello", func(name string) string {
+		return "hello " + name
+	}, Options{})
+	server.AddFunction("fail", func() error {
+		return errors.New("failed")
+	}, Options{})
+	server.Handle()
+	uri := server.URI()
+	client := NewTCPClient(uri)
+	var stub *recorderStub
+	client.UseService(&stub)
+	fixture := new(bytes.Buffer)
+	recorder := NewRecorder(client, fixture)
+	stub.Hello("a")
+	stub.Hello("b")
+	stub.Fail()
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	client.Close()
+	server.Close()
+	cases := []struct {
+		name    string
+		matcher RecordingMatcher
+		calls   []string
+		want    []string
+	}{
+		{"exact", nil,
+			[]string{"b", "a", "a"},
+			[]string{"hello b", "hello a", "hello a"}},
+		{"exact mismatch", MatchExact,
+			[]string{"c"},
+			[]string{"no recording matches the request: Hello"}},
+		{"method", MatchMethod,
+			[]string{"c", "d", "e"},
+			[]string{"hello a", "hello b", "hello b"}},
+		{"error", MatchMethod,
+			[]string{"fail"},
+			[]string{"failed"}},
+	}
+	for _, c := range cases {
+		replayer, err := NewReplayer(bytes.NewReader(fixture.Bytes()), c.matcher)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(replayer.Recordings()); n != 3 {
+			t.Fatalf("%d recordings, want 3", n)
+		}
+		client := NewTCPClient(uri)
+		replayer.Replay(client)
+		client.UseService(&stub)
+		for i, call := range c.calls {
+			var result string
+			if call == "fail" {
+				err = stub.Fail()
+			} else {
+				result, err = stub.Hello(call)
+			}
+			if err != nil {
+				result = err.Error()
+			}
+			if result != c.want[i] {
+				t.Errorf("%s: call %d returns %q, want %q",
+					c.name, i, result, c.want[i])
+			}
+			_, ok := err.(*ReplayError)
+			if ok != strings.HasPrefix(c.want[i], "no recording") {
+				t.Errorf("%s: call %d error %#v", c.name, i, err)
+			}
+		}
+		client.Close()
+	}
+}
@@ -0,0 +1,313 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/resolver.go                                        *
+ *                                                        *
+ * hprose service address resolver for Go.                *
+ *                                                        *
+ * LastModified: Oct 17, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resolver resolves the service addresses of hprose client
+type Resolver interface {
+	// Resolve returns the current service addresses, and then calls update
+	// with the new service addresses when they are changed, or with the error
+	// when the resolution is failed, until Close is called.
+	Resolve(update func(uriList []string, err error)) ([]string, error)
+	// Close stops the updates
+	Close()
+}
+
+// DefaultResolveInterval is the default interval of the polling resolvers
+const DefaultResolveInterval = 30 * time.Second
+
+// StaticResolver is a Resolver of a static list of service addresses,
+// which can be changed by Update.
+type StaticResolver struct {
+	uriList []string
+	updates []func(uriList []string, err error)
+	locker  sync.Mutex
+}
+
+// NewStaticResolver is the constructor of StaticResolver
+func NewStaticResolver(uri ...string) *StaticResolver {
+	return &StaticResolver{uriList: uri}
+}
+
+// Resolve returns the service addresses
+func (resolver *StaticResolver) Resolve(
+	update func(uriList []string, err error)) ([]string, error) {
+	resolver.locker.Lock()
+	defer resolver.locker.Unlock()
+	resolver.updates = append(resolver.updates, update)
+	return append([]string(nil), resolver.uriList...), nil
+}
+
+// Update changes the service addresses, and pushes them to the clients
+func (resolver *StaticResolver) Update(uri ...string) {
+	resolver.locker.Lock()
+	resolver.uriList = uri
+	updates := resolver.updates
+	resolver.locker.Unlock()
+	for _, update := range updates {
+		update(append([]string(nil), uri...), nil)
+	}
+}
+
+// Close stops the updates
+func (resolver *StaticResolver) Close() {
+	resolver.locker.Lock()
+	resolver.updates = nil
+	resolver.locker.Unlock()
+}
+
+// pollingResolver calls lookup at interval, and updates the service addresses
+// when they are changed.
+type pollingResolver struct {
+	interval time.Duration
+	lookup   func() ([]string, error)
+	dones    []chan struct{}
+	locker   sync.Mutex
+}
+
+func (resolver *pollingResolver) initPollingResolver(
+	interval time.Duration, lookup func() ([]string, error)) {
+	if interval <= 0 {
+		interval = DefaultResolveInterval
+	}
+	resolver.interval = interval
+	resolver.lookup = lookup
+}
+
+// Resolve returns the current service addresses, and then polls them at the
+// interval.
+func (resolver *pollingResolver) Resolve(
+	update func(uriList []string, err error)) ([]string, error) {
+	uriList, err := resolver.lookup()
+	if err != nil {
+		return nil, err
+	}
+	done := make(chan struct{})
+	resolver.locker.Lock()
+	resolver.dones = append(resolver.dones, done)
+	resolver.locker.Unlock()
+	go resolver.watch(done, uriList, update)
+	return uriList, nil
+}
+
+func (resolver *pollingResolver) watch(
+	done chan struct{}, last []string,
+	update func(uriList []string, err error)) {
+	ticker := time.NewTicker(resolver.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		uriList, err := resolver.lookup()
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if err != nil {
+			update(nil, err)
+		} else if !equalStringSet(last, uriList) {
+			last = uriList
+			update(uriList, nil)
+		}
+	}
+}
+
+// Close stops the polling
+func (resolver *pollingResolver) Close() {
+	resolver.locker.Lock()
+	for _, done := range resolver.dones {
+		close(done)
+	}
+	resolver.dones = nil
+	resolver.locker.Unlock()
+}
+
+func equalStringSet(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// FileResolver is a Resolver which reads the service addresses from a file,
+// and reloads them when the file is changed.
+//
+// The file is either a JSON array of the service addresses, or a text file
+// with one service address per line, the blank lines and the lines starting
+// with # are ignored.
+type FileResolver struct {
+	pollingResolver
+	filename string
+}
+
+// NewFileResolver is the constructor of FileResolver, the file is checked at
+// the interval, DefaultResolveInterval is used if it is not positive.
+func NewFileResolver(filename string, interval time.Duration) *FileResolver {
+	resolver := &FileResolver{filename: filename}
+	resolver.initPollingResolver(interval, resolver.readFile)
+	return resolver
+}
+
+func (resolver *FileResolver) readFile() ([]string, error) {
+	data, err := ioutil.ReadFile(resolver.filename)
+	if err != nil {
+		return nil, err
+	}
+	return parseURIList(data)
+}
+
+func parseURIList(data []byte) (uriList []string, err error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &uriList)
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && line[0] != '#' {
+			uriList = append(uriList, line)
+		}
+	}
+	return
+}
+
+// dnsLookupTimeout is the timeout of each DNS lookup of DNSResolver
+const dnsLookupTimeout = 10 * time.Second
+
+// DNSResolver is a Resolver which resolves the service addresses by the DNS
+// A/AAAA or SRV lookups.
+type DNSResolver struct {
+	pollingResolver
+	// NetResolver is used for the DNS lookups,
+	// net.DefaultResolver is used if it is nil.
+	NetResolver *net.Resolver
+	uri         *url.URL
+	service     string
+	proto       string
+}
+
+// NewDNSResolver returns a DNSResolver which looks up the IP addresses of the
+// host in uri, the service addresses are uri with the host replaced by each
+// of the IP addresses. The lookup is repeated at the interval,
+// DefaultResolveInterval is used if it is not positive.
+func NewDNSResolver(uri string, interval time.Duration) *DNSResolver {
+	u, err := url.Parse(uri)
+	if err != nil {
+		panic(err)
+	}
+	resolver := &DNSResolver{uri: u}
+	resolver.initPollingResolver(interval, resolver.lookupIP)
+	return resolver
+}
+
+// NewDNSSRVResolver returns a DNSResolver which looks up the SRV records of
+// the service, proto and the host in uri, the service addresses are uri with
+// the host and port replaced by the target and port of each of the records.
+// The lookup is repeated at the interval, DefaultResolveInterval is used if it
+// is not positive.
+func NewDNSSRVResolver(
+	uri string, service string, proto string,
+	interval time.Duration) *DNSResolver {
+	u, err := url.Parse(uri)
+	if err != nil {
+		panic(err)
+	}
+	resolver := &DNSResolver{uri: u, service: service, proto: proto}
+	resolver.initPollingResolver(interval, resolver.lookupSRV)
+	return resolver
+}
+
+func (resolver *DNSResolver) netResolver() *net.Resolver {
+	if resolver.NetResolver != nil {
+		return resolver.NetResolver
+	}
+	return net.DefaultResolver
+}
+
+func (resolver *DNSResolver) withHost(host string) string {
+	u := *resolver.uri
+	u.Host = host
+	return u.String()
+}
+
+func (resolver *DNSResolver) lookupIP() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dnsLookupTimeout)
+	defer cancel()
+	addrs, err := resolver.netResolver().LookupIPAddr(
+		ctx, resolver.uri.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	port := resolver.uri.Port()
+	uriList := make([]string, len(addrs))
+	for i, addr := range addrs {
+		host := addr.IP.String()
+		if port != "" {
+			host = net.JoinHostPort(host, port)
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		uriList[i] = resolver.withHost(host)
+	}
+	return uriList, nil
+}
+
+func (resolver *DNSResolver) lookupSRV() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dnsLookupTimeout)
+	defer cancel()
+	_, srvs, err := resolver.netResolver().LookupSRV(
+		ctx, resolver.service, resolver.proto, resolver.uri.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	uriList := make([]string, len(srvs))
+	for i, srv := range srvs {
+		target := strings.TrimSuffix(srv.Target, ".")
+		port := strconv.Itoa(int(srv.Port))
+		uriList[i] = resolver.withHost(net.JoinHostPort(target, port))
+	}
+	return uriList, nil
+}
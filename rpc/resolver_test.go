@@ -0,0 +1,186 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/resolver_test.go                                   *
+ *                                                        *
+ * hprose resolver test for Go.                           *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseURIList(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		want []string
+		err  bool
+	}{
+		{"json", ` ["tcp://a", "tcp://b"] `, []string{"tcp://a", "tcp://b"}, false},
+		{"lines", "tcp://a\r\n\n  tcp://b  \n", []string{"tcp://a", "tcp://b"}, false},
+		{"comments", "# services\ntcp://a\n#tcp://b\n", []string{"tcp://a"}, false},
+		{"empty", " \n", nil, false},
+		{"invalid json", `["tcp://a"`, nil, true},
+	}
+	for _, c := range cases {
+		uriList, err := parseURIList([]byte(c.data))
+		if (err != nil) != c.err {
+			t.Errorf("%s: error %v", c.name, err)
+		}
+		if !c.err && !reflect.DeepEqual(uriList, c.want) {
+			t.Errorf("%s: parse %q, want %q", c.name, uriList, c.want)
+		}
+	}
+}
+
+func TestEqualStringSet(t *testing.T) {
+	cases := []struct {
+		a, b []string
+		want bool
+	}{
+		{nil, nil, true},
+		{nil, []string{}, true},
+		{[]string{"b", "a"}, []string{"a", "b"}, true},
+		{[]string{"a", "b"}, []string{"a"}, false},
+		{[]string{"a", "b"}, []string{"a", "c"}, false},
+		{[]string{"a", "a", "b"}, []string{"a", "b", "b"}, false},
+	}
+	for _, c := range cases {
+		a := append([]string(nil), c.a...)
+		if got := equalStringSet(c.a, c.b); got != c.want {
+			t.Errorf("equalStringSet(%q, %q) is %v", c.a, c.b, got)
+		}
+		if len(a) > 0 && !reflect.DeepEqual(a, c.a) {
+			t.Errorf("equalStringSet changes its argument to %q", c.a)
+		}
+	}
+}
+
+type resolveErrorEvent chan error
+
+func (event resolveErrorEvent) OnResolveError(client Client, err error) {
+	event <- err
+}
+
+// waitURIList waits until the uri list of client is changed to want.
+func waitURIList(t *testing.T, client Client, want ...string) {
+	for i := 0; i < 200 && !equalStringSet(client.URIList(), want); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if uriList := client.URIList(); !equalStringSet(uriList, want) {
+		t.Fatalf("the uri list is %q, want %q", uriList, want)
+	}
+}
+
+func waitResolveError(t *testing.T, event resolveErrorEvent) error {
+	select {
+	case err := <-event:
+		return err
+	case <-time.After(2 * time.Second):
+		t.Fatal("the resolve error is not reported")
+	}
+	return nil
+}
+
+func TestStaticResolver(t *testing.T) {
+	resolver := NewStaticResolver("tcp://a", "tcp://b")
+	client := NewTCPClient("tcp://x")
+	defer client.Close()
+	event := make(resolveErrorEvent, 1)
+	client.SetEvent(event)
+	if err := client.SetResolver(resolver); err != nil {
+		t.Fatal(err)
+	}
+	waitURIList(t, client, "tcp://a", "tcp://b")
+	resolver.Update("tcp://c")
+	waitURIList(t, client, "tcp://c")
+	resolver.Update()
+	if err := waitResolveError(t, event); err != ErrNoServiceAddress {
+		t.Errorf("resolve error %v, want ErrNoServiceAddress", err)
+	}
+	waitURIList(t, client, "tcp://c")
+	client.SetResolver(nil)
+	resolver.Update("tcp://d")
+	waitURIList(t, client, "tcp://c")
+	if err := client.SetResolver(NewStaticResolver()); err != ErrNoServiceAddress {
+		t.Errorf("SetResolver returns %v, want ErrNoServiceAddress", err)
+	}
+}
+
+func TestFileResolver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hprose")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "services")
+	write := func(data string) {
+		if err := ioutil.WriteFile(filename, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client := NewTCPClient("tcp://x")
+	defer client.Close()
+	if err := client.SetResolver(
+		NewFileResolver(filename, 10*time.Millisecond)); err == nil {
+		t.Error("the missing file is resolved")
+	}
+	write("# services\ntcp://a\ntcp://b\n")
+	event := make(resolveErrorEvent, 10)
+	client.SetEvent(event)
+	if err := client.SetResolver(
+		NewFileResolver(filename, 10*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	waitURIList(t, client, "tcp://a", "tcp://b")
+	write(`["tcp://c", "tcp://a"]`)
+	waitURIList(t, client, "tcp://a", "tcp://c")
+	os.Remove(filename)
+	if err := waitResolveError(t, event); !os.IsNotExist(err) {
+		t.Errorf("resolve error %v, want not exist", err)
+	}
+	waitURIList(t, client, "tcp://a", "tcp://c")
+	write("tcp://d")
+	waitURIList(t, client, "tcp://d")
+}
+
+func TestDNSResolver(t *testing.T) {
+	cases := []struct {
+		uri  string
+		want string
+	}{
+		{"tcp://127.0.0.1:4321", "tcp://127.0.0.1:4321"},
+		{"tcp://[::1]:4321", "tcp://[::1]:4321"},
+		{"http://127.0.0.1/hprose", "http://127.0.0.1/hprose"},
+		{"ws://[::1]/hprose", "ws://[::1]/hprose"},
+	}
+	for _, c := range cases {
+		resolver := NewDNSResolver(c.uri, 0)
+		uriList, err := resolver.Resolve(func([]string, error) {})
+		resolver.Close()
+		if err != nil {
+			t.Errorf("%s: %v", c.uri, err)
+		} else if !reflect.DeepEqual(uriList, []string{c.want}) {
+			t.Errorf("%s: resolve %q, want %q", c.uri, uriList, c.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/response_cache.go                                  *
+ *                                                        *
+ * hprose client response cache for Go.                   *
+ *                                                        *
+ * LastModified: Oct 17, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultResponseCacheSize is the default max entries of the ResponseCache
+const DefaultResponseCacheSize = 1024
+
+// ResponseCache is a LRU cache of the responses of the remote methods which
+// are invoked with the InvokeSettings.Cache, the key of the cache is the
+// encoded request, so the invocations of the same method with the same
+// arguments share the cached response.
+type ResponseCache struct {
+	size    int
+	entries map[string]*list.Element
+	lru     *list.List
+	locker  sync.Mutex
+}
+
+type cacheEntry struct {
+	key      string
+	method   string
+	response []byte
+	expires  time.Time
+}
+
+// NewResponseCache is the constructor of ResponseCache, size is the max
+// entries of the cache, DefaultResponseCacheSize is used if it is not
+// positive.
+func NewResponseCache(size int) *ResponseCache {
+	if size <= 0 {
+		size = DefaultResponseCacheSize
+	}
+	return &ResponseCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// get returns a copy of the cached response of the request
+func (cache *ResponseCache) get(request []byte) ([]byte, bool) {
+	cache.locker.Lock()
+	defer cache.locker.Unlock()
+	element, ok := cache.entries[string(request)]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		cache.remove(element)
+		return nil, false
+	}
+	cache.lru.MoveToFront(element)
+	return append([]byte(nil), entry.response...), true
+}
+
+// set caches a copy of the response of the request for ttl
+func (cache *ResponseCache) set(
+	method string, request []byte, response []byte, ttl time.Duration) {
+	entry := &cacheEntry{
+		key:      string(request),
+		method:   method,
+		response: append([]byte(nil), response...),
+		expires:  time.Now().Add(ttl),
+	}
+	cache.locker.Lock()
+	defer cache.locker.Unlock()
+	if element, ok := cache.entries[entry.key]; ok {
+		element.Value = entry
+		cache.lru.MoveToFront(element)
+		return
+	}
+	cache.entries[entry.key] = cache.lru.PushFront(entry)
+	for cache.lru.Len() > cache.size {
+		cache.remove(cache.lru.Back())
+	}
+}
+
+func (cache *ResponseCache) remove(element *list.Element) {
+	cache.lru.Remove(element)
+	delete(cache.entries, element.Value.(*cacheEntry).key)
+}
+
+// Invalidate removes the cached responses of the methods, all of the cached
+// responses are removed if no method is specified.
+func (cache *ResponseCache) Invalidate(method ...string) {
+	cache.locker.Lock()
+	defer cache.locker.Unlock()
+	if len(method) == 0 {
+		cache.entries = make(map[string]*list.Element)
+		cache.lru.Init()
+		return
+	}
+	for element := cache.lru.Front(); element != nil; {
+		next := element.Next()
+		name := element.Value.(*cacheEntry).method
+		for _, m := range method {
+			if strings.EqualFold(name, m) {
+				cache.remove(element)
+				break
+			}
+		}
+		element = next
+	}
+}
+
+// Len returns the number of the cached responses
+func (cache *ResponseCache) Len() int {
+	cache.locker.Lock()
+	defer cache.locker.Unlock()
+	return cache.lru.Len()
+}
@@ -0,0 +1,155 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/response_cache_test.go                             *
+ *                                                        *
+ * hprose response cache test for Go.                     *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResponseCacheLRU(t *testing.T) {
+	cache := NewResponseCache(2)
+	steps := []struct {
+		op   string
+		key  string
+		want bool
+	}{
+		{"set", "a", true},
+		{"set", "b", true},
+		{"get", "a", true},
+		{"set", "c", true},
+		{"get", "b", false},
+		{"get", "a", true},
+		{"get", "c", true},
+		{"set", "d", true},
+		{"get", "a", false},
+		{"get", "c", true},
+		{"get", "d", true},
+	}
+	for i, step := range steps {
+		switch step.op {
+		case "set":
+			cache.set("method", []byte(step.key), []byte(step.key), time.Minute)
+		case "get":
+			response, ok := cache.get([]byte(step.key))
+			if ok != step.want {
+				t.Fatalf("step %d: get %s hit %v, want %v", i, step.key, ok, step.want)
+			}
+			if ok && string(response) != step.key {
+				t.Fatalf("step %d: get %s returns %s", i, step.key, response)
+			}
+		}
+		if n := cache.Len(); n > 2 {
+			t.Fatalf("step %d: %d entries are cached, want at most 2", i, n)
+		}
+	}
+}
+
+func TestResponseCacheTTL(t *testing.T) {
+	cache := NewResponseCache(0)
+	cache.set("method", []byte("short"), []byte("1"), 20*time.Millisecond)
+	cache.set("method", []byte("long"), []byte("2"), time.Minute)
+	if _, ok := cache.get([]byte("short")); !ok {
+		t.Fatal("the response is expired before its ttl")
+	}
+	time.Sleep(40 * time.Millisecond)
+	if _, ok := cache.get([]byte("short")); ok {
+		t.Error("the response is not expired after its ttl")
+	}
+	if _, ok := cache.get([]byte("long")); !ok {
+		t.Error("the response is expired before its ttl")
+	}
+	if n := cache.Len(); n != 1 {
+		t.Errorf("%d entries are cached, want 1", n)
+	}
+}
+
+func TestResponseCacheInvalidate(t *testing.T) {
+	cache := NewResponseCache(0)
+	cache.set("hello", []byte("1"), nil, time.Minute)
+	cache.set("Hello", []byte("2"), nil, time.Minute)
+	cache.set("sum", []byte("3"), nil, time.Minute)
+	cache.set("max", []byte("4"), nil, time.Minute)
+	cache.Invalidate("HELLO", "sum")
+	if n := cache.Len(); n != 1 {
+		t.Errorf("%d entries are cached, want 1", n)
+	}
+	if _, ok := cache.get([]byte("4")); !ok {
+		t.Error("the response of the other method is invalidated")
+	}
+	cache.Invalidate()
+	if n := cache.Len(); n != 0 {
+		t.Errorf("%d entries are cached, want 0", n)
+	}
+}
+
+func TestResponseCacheInvoke(t *testing.T) {
+	var calls int32
+	server := NewTCPServer("")
+	server.AddFunction("sum", func(a, b int) int {
+		atomic.AddInt32(&calls, 1)
+		return a + b
+	}, Options{})
+	server.AddFunction("fail", func() (int, error) {
+		atomic.AddInt32(&calls, 1)
+		return 0, errors.New("failed")
+	}, Options{})
+	server.Handle()
+	defer server.Close()
+	client := NewTCPClient(server.URI())
+	defer client.Close()
+	cached := &InvokeSettings{Cache: time.Minute}
+	cases := []struct {
+		name     string
+		method   string
+		args     []interface{}
+		settings *InvokeSettings
+		calls    int32
+	}{
+		{"first call", "sum", []interface{}{1, 2}, cached, 1},
+		{"same args", "sum", []interface{}{1, 2}, cached, 0},
+		{"other args", "sum", []interface{}{2, 3}, cached, 1},
+		{"not cached", "sum", []interface{}{1, 2}, nil, 1},
+		{"error", "fail", nil, cached, 1},
+		{"error is not cached", "fail", nil, cached, 1},
+	}
+	for _, c := range cases {
+		args := make([]reflect.Value, len(c.args))
+		for i, arg := range c.args {
+			args[i] = reflect.ValueOf(arg)
+		}
+		atomic.StoreInt32(&calls, 0)
+		client.Invoke(c.method, args, c.settings)
+		if n := atomic.LoadInt32(&calls); n != c.calls {
+			t.Errorf("%s: the service is called %d times, want %d",
+				c.name, n, c.calls)
+		}
+	}
+	client.ResponseCache().Invalidate("sum")
+	atomic.StoreInt32(&calls, 0)
+	client.Invoke("sum", []reflect.Value{
+		reflect.ValueOf(1), reflect.ValueOf(2)}, cached)
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("the invalidated response is used: %d calls", n)
+	}
+}
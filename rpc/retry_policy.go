@@ -0,0 +1,198 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/retry_policy.go                                    *
+ *                                                        *
+ * hprose retry policy for client.                        *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/url"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy decides whether the failed request is retried
+type RetryPolicy interface {
+	// Retry returns the delay before the next retry and true if the request
+	// which is failed with err should be retried, context.Retried is the
+	// number of the retries which have been done.
+	Retry(context *ClientContext, err error) (delay time.Duration, retry bool)
+}
+
+// ExponentialBackoff is a RetryPolicy which retries the request after an
+// exponential backoff with full jitter. The delay before the nth retry is a
+// random duration between 0 and min(MaxDelay, BaseDelay * Multiplier^n).
+//
+// The Idempotent request is retried at most context.Retry times if the error
+// is retryable, the other request is only retried if it is not sent.
+//
+// The zero Multiplier is 2, and the zero MaxDelay means no limit.
+type ExponentialBackoff struct {
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	Multiplier float64
+	// Retryable reports whether the error is retryable,
+	// IsRetryableError is used if it is nil.
+	Retryable func(err error) bool
+	// RetryServerError indicates the error returned by the server is retryable
+	RetryServerError bool
+	rand             *rand.Rand
+	locker           sync.Mutex
+}
+
+// NewExponentialBackoff is the constructor of ExponentialBackoff
+func NewExponentialBackoff() *ExponentialBackoff {
+	return &ExponentialBackoff{
+		BaseDelay:  100 * time.Millisecond,
+		MaxDelay:   10 * time.Second,
+		Multiplier: 2,
+	}
+}
+
+// Retry returns the delay before the next retry
+func (policy *ExponentialBackoff) Retry(
+	context *ClientContext, err error) (time.Duration, bool) {
+	if context.Retried >= context.Retry || !policy.retryable(err) {
+		return 0, false
+	}
+	if !context.Idempotent && !isNotSentError(err) {
+		return 0, false
+	}
+	multiplier := policy.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	delay := float64(policy.BaseDelay) *
+		math.Pow(multiplier, float64(context.Retried))
+	if max := float64(policy.MaxDelay); max > 0 && delay > max {
+		delay = max
+	}
+	if delay < 1 {
+		return 0, true
+	}
+	policy.locker.Lock()
+	if policy.rand == nil {
+		policy.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	delay = policy.rand.Float64() * delay
+	policy.locker.Unlock()
+	return time.Duration(delay), true
+}
+
+func (policy *ExponentialBackoff) retryable(err error) bool {
+	if _, ok := err.(*ServerError); ok {
+		return policy.RetryServerError
+	}
+	if policy.Retryable != nil {
+		return policy.Retryable(err)
+	}
+	return IsRetryableError(err)
+}
+
+func unwrapError(err error) error {
+	for {
+		switch e := err.(type) {
+		case *DialError:
+			err = e.Err
+		case *url.Error:
+			err = e.Err
+		case *net.OpError:
+			err = e.Err
+		case *os.SyscallError:
+			err = e.Err
+		default:
+			return err
+		}
+	}
+}
+
+// IsRetryableError reports whether the error is a timeout, a connection error
+// or ErrCircuitOpen.
+func IsRetryableError(err error) bool {
+	if isNotSentError(err) {
+		return true
+	}
+	if e, ok := err.(net.Error); ok && e.Timeout() {
+		return true
+	}
+	switch unwrapError(err) {
+	case ErrTimeout, io.EOF, io.ErrUnexpectedEOF,
+		syscall.ECONNRESET, syscall.ECONNABORTED, syscall.EPIPE:
+		return true
+	}
+	return false
+}
+
+// isNotSentError reports whether the request failed with err is not sent
+func isNotSentError(err error) bool {
+	if err == ErrCircuitOpen {
+		return true
+	}
+	if _, ok := err.(*DialError); ok {
+		return true
+	}
+	if e, ok := err.(*url.Error); ok {
+		err = e.Err
+	}
+	if e, ok := err.(*net.OpError); ok && e.Op == "dial" {
+		return true
+	}
+	return unwrapError(err) == syscall.ECONNREFUSED
+}
+
+// RetryBudget limits the retries of the client, so the failure of the server
+// can't multiply the requests. Every request deposits Ratio token to the
+// budget, and every retry withdraws one token, the retry is not allowed if
+// there is not enough token.
+type RetryBudget struct {
+	// Ratio is the max ratio of the retries to the requests
+	Ratio float64
+	// MaxTokens is the max tokens in the budget, which is also the initial
+	// tokens, it allows the retries before enough requests are sent.
+	MaxTokens float64
+	tokens    float64
+	locker    sync.Mutex
+}
+
+// NewRetryBudget is the constructor of RetryBudget
+func NewRetryBudget(ratio float64, maxTokens float64) *RetryBudget {
+	return &RetryBudget{Ratio: ratio, MaxTokens: maxTokens, tokens: maxTokens}
+}
+
+func (budget *RetryBudget) deposit() {
+	budget.locker.Lock()
+	if budget.tokens += budget.Ratio; budget.tokens > budget.MaxTokens {
+		budget.tokens = budget.MaxTokens
+	}
+	budget.locker.Unlock()
+}
+
+func (budget *RetryBudget) withdraw() bool {
+	budget.locker.Lock()
+	defer budget.locker.Unlock()
+	if budget.tokens < 1 {
+		return false
+	}
+	budget.tokens--
+	return true
+}
@@ -0,0 +1,232 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/retry_policy_test.go                               *
+ *                                                        *
+ * hprose retry policy test for Go.                       *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type timeoutNetError struct{}
+
+func (timeoutNetError) Error() string   { return "i/o timeout" }
+func (timeoutNetError) Timeout() bool   { return true }
+func (timeoutNetError) Temporary() bool { return true }
+
+func TestIsRetryableError(t *testing.T) {
+	dialError := &net.OpError{Op: "dial", Net: "tcp", Err: &os.SyscallError{
+		Syscall: "connect", Err: syscall.ECONNREFUSED}}
+	resetError := &net.OpError{Op: "read", Net: "tcp", Err: &os.SyscallError{
+		Syscall: "read", Err: syscall.ECONNRESET}}
+	tests := []struct {
+		err       error
+		retryable bool
+		notSent   bool
+	}{
+		{errors.New("error"), false, false},
+		{&ServerError{"error"}, false, false},
+		{context.Canceled, false, false},
+		{ErrTimeout, true, false},
+		{ErrCircuitOpen, true, true},
+		{io.EOF, true, false},
+		{io.ErrUnexpectedEOF, true, false},
+		{timeoutNetError{}, true, false},
+		{syscall.EPIPE, true, false},
+		{resetError, true, false},
+		{&url.Error{Op: "Post", URL: "http://localhost", Err: resetError},
+			true, false},
+		{dialError, true, true},
+		{&url.Error{Op: "Post", URL: "http://localhost", Err: dialError},
+			true, true},
+		{&DialError{"tcp://localhost", dialError}, true, true},
+		{&DialError{"tcp://localhost", timeoutNetError{}}, true, true},
+	}
+	for _, test := range tests {
+		if r := IsRetryableError(test.err); r != test.retryable {
+			t.Errorf("IsRetryableError(%v) = %v, want %v",
+				test.err, r, test.retryable)
+		}
+		if r := isNotSentError(test.err); r != test.notSent {
+			t.Errorf("isNotSentError(%v) = %v, want %v",
+				test.err, r, test.notSent)
+		}
+	}
+}
+
+func TestExponentialBackoffBounds(t *testing.T) {
+	policy := NewExponentialBackoff()
+	policy.BaseDelay = 100 * time.Millisecond
+	policy.MaxDelay = time.Second
+	tests := []struct {
+		name   string
+		policy *ExponentialBackoff
+	}{
+		{"NewExponentialBackoff", policy},
+		{"struct literal", &ExponentialBackoff{
+			BaseDelay: 100 * time.Millisecond,
+			MaxDelay:  time.Second,
+		}},
+		{"no MaxDelay", &ExponentialBackoff{BaseDelay: time.Millisecond}},
+	}
+	for _, test := range tests {
+		context := &ClientContext{}
+		context.Idempotent = true
+		context.Retry = 10
+		for retried := 0; retried < 10; retried++ {
+			context.Retried = retried
+			max := test.policy.BaseDelay << uint(retried)
+			if test.policy.MaxDelay > 0 && max > test.policy.MaxDelay {
+				max = test.policy.MaxDelay
+			}
+			for i := 0; i < 100; i++ {
+				delay, retry := test.policy.Retry(context, ErrTimeout)
+				if !retry || delay < 0 || delay > max {
+					t.Fatalf("%s: retried: %d, delay: %v, retry: %v, max: %v",
+						test.name, retried, delay, retry, max)
+				}
+			}
+		}
+		context.Retried = 10
+		if _, retry := test.policy.Retry(context, ErrTimeout); retry {
+			t.Errorf("%s: retries exceed context.Retry", test.name)
+		}
+	}
+}
+
+func TestExponentialBackoffRetry(t *testing.T) {
+	tests := []struct {
+		idempotent       bool
+		err              error
+		retryServerError bool
+		retry            bool
+	}{
+		{true, ErrTimeout, false, true},
+		{false, ErrTimeout, false, false},
+		{false, ErrCircuitOpen, false, true},
+		{false, &DialError{"tcp://localhost", syscall.ECONNREFUSED}, false, true},
+		{true, errors.New("error"), false, false},
+		{true, &ServerError{"error"}, false, false},
+		{true, &ServerError{"error"}, true, true},
+		{false, &ServerError{"error"}, true, false},
+	}
+	for _, test := range tests {
+		policy := NewExponentialBackoff()
+		policy.RetryServerError = test.retryServerError
+		context := &ClientContext{}
+		context.Idempotent = test.idempotent
+		context.Retry = 1
+		if _, retry := policy.Retry(context, test.err); retry != test.retry {
+			t.Errorf("idempotent: %v, err: %v, retryServerError: %v, "+
+				"retry is %v, want %v", test.idempotent, test.err,
+				test.retryServerError, retry, test.retry)
+		}
+	}
+	policy := NewExponentialBackoff()
+	policy.Retryable = func(err error) bool { return err == io.EOF }
+	context := &ClientContext{}
+	context.Idempotent = true
+	context.Retry = 1
+	if _, retry := policy.Retry(context, ErrTimeout); retry {
+		t.Error("Retryable is ignored")
+	}
+	if _, retry := policy.Retry(context, io.EOF); !retry {
+		t.Error("Retryable is ignored")
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	budget := NewRetryBudget(0.5, 2)
+	if !budget.withdraw() || !budget.withdraw() {
+		t.Fatal("the initial tokens can't be withdrawn")
+	}
+	if budget.withdraw() {
+		t.Fatal("the empty budget is withdrawn")
+	}
+	budget.deposit()
+	if budget.withdraw() {
+		t.Fatal("half token is withdrawn")
+	}
+	budget.deposit()
+	if !budget.withdraw() {
+		t.Fatal("two deposits don't allow a retry")
+	}
+	for i := 0; i < 10; i++ {
+		budget.deposit()
+	}
+	for i := 0; i < 2; i++ {
+		if !budget.withdraw() {
+			t.Fatal("MaxTokens can't be withdrawn")
+		}
+	}
+	if budget.withdraw() {
+		t.Fatal("the tokens exceed MaxTokens")
+	}
+}
+
+func TestDialErrorIsRetryable(t *testing.T) {
+	err := &DialError{"tcp://127.0.0.1:1", &net.OpError{
+		Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}
+	if !isNotSentError(err) {
+		t.Error("DialError is sent")
+	}
+	if !IsRetryableError(err) {
+		t.Error("DialError isn't retryable")
+	}
+	if e := unwrapError(err); e != syscall.ECONNREFUSED {
+		t.Errorf("unwrapError returns %v, want %v", e, syscall.ECONNREFUSED)
+	}
+}
+
+func TestRetryRefusedDial(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := "tcp://" + listener.Addr().String()
+	listener.Close()
+	client := NewTCPClient(uri)
+	defer client.Close()
+	policy := NewExponentialBackoff()
+	policy.BaseDelay = time.Millisecond
+	client.SetRetryPolicy(policy)
+	client.SetRetry(3)
+	var dials int32
+	client.DialContext = func(
+		ctx context.Context, network, address string) (net.Conn, error) {
+		atomic.AddInt32(&dials, 1)
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, network, address)
+	}
+	_, err = client.Invoke("hello", nil, &InvokeSettings{})
+	if _, ok := err.(*DialError); !ok {
+		t.Fatalf("err is %T %v, want *DialError", err, err)
+	}
+	if dials != 4 {
+		t.Fatalf("dials is %d, want 4", dials)
+	}
+}
@@ -12,7 +12,7 @@
  *                                                        *
  * hprose service for Go.                                 *
  *                                                        *
- * LastModified: Sep 23, 2016                             *
+ * LastModified: Oct 17, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -25,6 +25,7 @@ import "time"
 type Service interface {
 	AddFunction(name string, function interface{}, options Options) Service
 	AddFunctions(names []string, functions []interface{}, options Options) Service
+	AddInvoker(name string, invoker Invoker, options Options) Service
 	AddMethod(name string, obj interface{}, options Options, alias ...string) Service
 	AddMethods(names []string, obj interface{}, options Options, aliases ...[]string) Service
 	AddInstanceMethods(obj interface{}, options Options) Service
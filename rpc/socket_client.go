@@ -12,7 +12,7 @@
  *                                                        *
  * hprose socket client for Go.                           *
  *                                                        *
- * LastModified: Oct 8, 2016                              *
+ * LastModified: Oct 18, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -20,6 +20,7 @@
 package rpc
 
 import (
+	"context"
 	"crypto/tls"
 	"net"
 	"runtime"
@@ -30,36 +31,209 @@ import (
 
 type connEntry struct {
 	conn      net.Conn
+	pool      *connPool
 	timer     *time.Timer
 	reqCount  int32
 	cond      *sync.Cond
 	responses map[uint32]chan socketResponse
 }
 
+// connPool is the conn pool of a service address
+type connPool struct {
+	cond    sync.Cond
+	entries []*connEntry
+	size    int
+	count   int
+	closed  bool
+}
+
+func newConnPool(size int) *connPool {
+	pool := &connPool{size: size}
+	pool.cond.L = &sync.Mutex{}
+	return pool
+}
+
+// get returns an idle conn entry or nil, pool.cond.L must be locked.
+func (pool *connPool) get() *connEntry {
+	n := len(pool.entries)
+	if n == 0 {
+		return nil
+	}
+	entry := pool.entries[n-1]
+	pool.entries[n-1] = nil
+	pool.entries = pool.entries[:n-1]
+	if entry.timer != nil {
+		entry.timer.Stop()
+	}
+	return entry
+}
+
+// remove removes the entry from the idle entries, pool.cond.L must be locked.
+func (pool *connPool) remove(entry *connEntry) bool {
+	for i, e := range pool.entries {
+		if e == entry {
+			n := len(pool.entries) - 1
+			copy(pool.entries[i:], pool.entries[i+1:])
+			pool.entries[n] = nil
+			pool.entries = pool.entries[:n]
+			return true
+		}
+	}
+	return false
+}
+
+// getConn returns the conn of the entry, it is nil if the full duplex conn is
+// broken.
+func (entry *connEntry) getConn() net.Conn {
+	if entry.cond == nil {
+		return entry.conn
+	}
+	entry.cond.L.Lock()
+	defer entry.cond.L.Unlock()
+	return entry.conn
+}
+
+// close closes the conn of the entry which is removed from the pool.
+func (entry *connEntry) close() {
+	if entry.timer != nil {
+		entry.timer.Stop()
+	}
+	if conn := entry.getConn(); conn != nil {
+		conn.Close()
+	}
+}
+
+// halfDuplexCount returns the number of the half duplex entries, the full
+// duplex entries are uncounted by fullDuplexReceive after their conns are
+// closed.
+func halfDuplexCount(entries []*connEntry) (n int) {
+	for _, entry := range entries {
+		if entry.cond == nil {
+			n++
+		}
+	}
+	return
+}
+
+func (pool *connPool) put(entry *connEntry, idleTimeout time.Duration) {
+	pool.cond.L.Lock()
+	conn := entry.getConn()
+	if conn == nil {
+		// the broken full duplex conn is already removed and uncounted by
+		// fullDuplexReceive.
+		pool.cond.L.Unlock()
+		return
+	}
+	if pool.closed || len(pool.entries) >= pool.size {
+		if entry.cond == nil {
+			pool.count--
+		}
+		pool.cond.L.Unlock()
+		pool.cond.Signal()
+		conn.Close()
+		return
+	}
+	if entry.cond == nil && idleTimeout > 0 {
+		if entry.timer == nil {
+			entry.timer = time.AfterFunc(idleTimeout, func() {
+				pool.cond.L.Lock()
+				removed := pool.remove(entry)
+				if removed {
+					pool.count--
+				}
+				pool.cond.L.Unlock()
+				if removed {
+					pool.cond.Signal()
+					entry.conn.Close()
+				}
+			})
+		} else {
+			entry.timer.Reset(idleTimeout)
+		}
+	}
+	pool.entries = append(pool.entries, entry)
+	pool.cond.L.Unlock()
+	pool.cond.Signal()
+}
+
+func (pool *connPool) close(conn net.Conn) {
+	conn.Close()
+	pool.cond.L.Lock()
+	pool.count--
+	pool.cond.L.Unlock()
+	pool.cond.Signal()
+}
+
+func (pool *connPool) resize(size int) {
+	var entries []*connEntry
+	pool.cond.L.Lock()
+	pool.size = size
+	if n := len(pool.entries); n > size {
+		entries = make([]*connEntry, n-size)
+		copy(entries, pool.entries[size:])
+		for i := size; i < n; i++ {
+			pool.entries[i] = nil
+		}
+		pool.entries = pool.entries[:size]
+		pool.count -= halfDuplexCount(entries)
+	}
+	pool.cond.L.Unlock()
+	pool.cond.Broadcast()
+	for _, entry := range entries {
+		entry.close()
+	}
+}
+
+func (pool *connPool) closeAll() {
+	pool.cond.L.Lock()
+	pool.closed = true
+	entries := pool.entries
+	pool.count -= halfDuplexCount(entries)
+	pool.entries = nil
+	pool.cond.L.Unlock()
+	pool.cond.Broadcast()
+	for _, entry := range entries {
+		entry.close()
+	}
+}
+
+// DefaultDialTimeout is the default DialTimeout of SocketClient
+const DefaultDialTimeout = 30 * time.Second
+
 // SocketClient is base struct for TCPClient and UnixClient
 type SocketClient struct {
 	BaseClient
 	ReadBuffer  int
 	WriteBuffer int
 	IdleTimeout time.Duration
+	// DialTimeout is the maximum time of connecting, 0 means no limit
+	// besides the timeout of the call.
+	DialTimeout time.Duration
+	// DialContext connects the service address, for example through a
+	// proxy. net.Dialer is used if it is nil.
+	DialContext func(ctx context.Context, network, address string) (net.Conn, error)
 	TLSConfig   *tls.Config
-	connPool    chan *connEntry
-	connCount   int32
+	pools       map[string]*connPool
+	poolSize    int
+	poolLocker  sync.Mutex
+	closed      bool
 	nextid      uint32
-	createConn  func() net.Conn
-	cond        sync.Cond
+	createConn  func(ctx context.Context, uri string) (net.Conn, error)
 }
 
 func (client *SocketClient) initSocketClient() {
 	client.initBaseClient()
+	client.closeURIs = client.closePools
 	client.ReadBuffer = 0
 	client.WriteBuffer = 0
 	client.IdleTimeout = 30 * time.Second
+	client.DialTimeout = DefaultDialTimeout
+	client.DialContext = nil
 	client.TLSConfig = nil
-	client.connPool = make(chan *connEntry, runtime.NumCPU()*2)
-	client.connCount = 0
+	client.pools = make(map[string]*connPool)
+	client.poolSize = runtime.NumCPU() * 2
+	client.closed = false
 	client.nextid = 0
-	client.cond.L = &sync.Mutex{}
 	client.SetFullDuplex(false)
 }
 
@@ -82,41 +256,52 @@ func (client *SocketClient) SetFullDuplex(fullDuplex bool) {
 	}
 }
 
-// MaxPoolSize returns the max conn pool size of hprose socket client
+// MaxPoolSize returns the max conn pool size of every service address
 func (client *SocketClient) MaxPoolSize() int {
-	return cap(client.connPool)
+	client.poolLocker.Lock()
+	defer client.poolLocker.Unlock()
+	return client.poolSize
 }
 
-// SetMaxPoolSize sets the max conn pool size of hprose socket client
+// SetMaxPoolSize sets the max conn pool size of every service address
 func (client *SocketClient) SetMaxPoolSize(size int) {
-	pool := make(chan *connEntry, size)
-	for i := 0; i < len(client.connPool); i++ {
-		select {
-		case pool <- <-client.connPool:
-		default:
-		}
+	client.poolLocker.Lock()
+	defer client.poolLocker.Unlock()
+	client.poolSize = size
+	for _, pool := range client.pools {
+		pool.resize(size)
 	}
-	client.connPool = pool
 }
 
-func (client *SocketClient) getConn() *connEntry {
-	for {
-		select {
-		case entry, closed := <-client.connPool:
-			if !closed {
-				panic(errClientIsAlreadyClosed)
-			}
-			if entry.timer != nil {
-				entry.timer.Stop()
-			}
-			if entry.conn != nil {
-				return entry
-			}
-			continue
-		default:
-			return nil
+func (client *SocketClient) getPool(uri string) (*connPool, error) {
+	client.poolLocker.Lock()
+	defer client.poolLocker.Unlock()
+	if client.closed {
+		return nil, errClientIsAlreadyClosed
+	}
+	pool := client.pools[uri]
+	if pool == nil {
+		pool = newConnPool(client.poolSize)
+		client.pools[uri] = pool
+	}
+	return pool, nil
+}
+
+// closePools drains the conn pools of the removed service addresses, the idle
+// conns are closed at once, the others are closed after they are released.
+func (client *SocketClient) closePools(uriList []string) {
+	pools := make([]*connPool, 0, len(uriList))
+	client.poolLocker.Lock()
+	for _, uri := range uriList {
+		if pool := client.pools[uri]; pool != nil {
+			pools = append(pools, pool)
+			delete(client.pools, uri)
 		}
 	}
+	client.poolLocker.Unlock()
+	for _, pool := range pools {
+		pool.closeAll()
+	}
 }
 
 func (client *SocketClient) fullDuplexReceive(entry *connEntry) {
@@ -125,18 +310,21 @@ func (client *SocketClient) fullDuplexReceive(entry *connEntry) {
 	for {
 		err := recvData(conn, &data)
 		if err != nil {
-			if entry.responses != nil {
-				entry.cond.L.Lock()
-				responses := entry.responses
-				entry.conn = nil
-				entry.reqCount = 0
-				entry.responses = nil
-				entry.cond.L.Unlock()
-				entry.cond.Broadcast()
-				client.close(conn)
-				for _, response := range responses {
-					response <- socketResponse{nil, err}
-				}
+			// conn is cleared before the entry is removed, so the pool
+			// never puts it back after it is removed.
+			entry.cond.L.Lock()
+			responses := entry.responses
+			entry.conn = nil
+			entry.reqCount = 0
+			entry.responses = nil
+			entry.cond.L.Unlock()
+			entry.cond.Broadcast()
+			entry.pool.cond.L.Lock()
+			entry.pool.remove(entry)
+			entry.pool.cond.L.Unlock()
+			entry.pool.close(conn)
+			for _, response := range responses {
+				response <- socketResponse{nil, err}
 			}
 			break
 		}
@@ -153,71 +341,196 @@ func (client *SocketClient) fullDuplexReceive(entry *connEntry) {
 	}
 }
 
-func (client *SocketClient) fetchConn(fullDuplex bool) *connEntry {
-	client.cond.L.Lock()
+func (client *SocketClient) fetchConn(
+	ctx context.Context, uri string, fullDuplex bool) (*connEntry, error) {
+	pool, err := client.getPool(uri)
+	if err != nil {
+		return nil, err
+	}
+	pool.cond.L.Lock()
+	var stop func()
+	defer func() {
+		if stop != nil {
+			stop()
+		}
+	}()
 	for {
-		entry := client.getConn()
-		if entry != nil && entry.conn != nil {
-			client.cond.L.Unlock()
-			return entry
+		if pool.closed {
+			pool.cond.L.Unlock()
+			if stop != nil {
+				stop()
+				stop = nil
+			}
+			// the pool of the removed service address is drained,
+			// getPool returns a new pool unless the client is closed.
+			if pool, err = client.getPool(uri); err != nil {
+				return nil, err
+			}
+			pool.cond.L.Lock()
+			continue
+		}
+		if entry := pool.get(); entry != nil {
+			pool.cond.L.Unlock()
+			return entry, nil
 		}
-		if int(atomic.AddInt32(&client.connCount, 1)) <= cap(client.connPool) {
-			client.cond.L.Unlock()
-			entry := &connEntry{conn: client.createConn()}
+		if pool.count < pool.size {
+			pool.count++
+			pool.cond.L.Unlock()
+			conn, err := client.createConn(ctx, uri)
+			if err != nil {
+				pool.cond.L.Lock()
+				pool.count--
+				pool.cond.L.Unlock()
+				pool.cond.Signal()
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				return nil, err
+			}
+			entry := &connEntry{conn: conn, pool: pool}
 			if fullDuplex {
 				entry.cond = sync.NewCond(&sync.Mutex{})
 				entry.responses = make(map[uint32]chan socketResponse, 10)
 				go client.fullDuplexReceive(entry)
 			}
-			return entry
+			return entry, nil
+		}
+		if err := ctx.Err(); err != nil {
+			pool.cond.L.Unlock()
+			return nil, err
+		}
+		if stop == nil {
+			stop = watchContext(ctx, &pool.cond)
 		}
-		atomic.AddInt32(&client.connCount, -1)
-		client.cond.Wait()
+		pool.cond.Wait()
 	}
 }
 
-func ifErrorPanic(err error) {
+// abortConn interrupts the blocking io of conn when ctx is done,
+// the returned stop func must be called after the io is finished.
+func abortConn(ctx context.Context, conn net.Conn) (stop func()) {
+	done := ctx.Done()
+	if done == nil {
+		return func() {}
+	}
+	stopped := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		select {
+		case <-done:
+			conn.SetDeadline(time.Now())
+		case <-stopped:
+		}
+		close(exited)
+	}()
+	return func() {
+		close(stopped)
+		<-exited
+	}
+}
+
+// dial connects address with DialContext in DialTimeout.
+func (client *SocketClient) dial(
+	ctx context.Context, network, address string) (net.Conn, error) {
+	ctx, cancel := withTimeout(ctx, client.DialTimeout)
+	defer cancel()
+	if client.DialContext != nil {
+		return client.DialContext(ctx, network, address)
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, network, address)
+}
+
+type bufferedConn interface {
+	SetReadBuffer(bytes int) error
+	SetWriteBuffer(bytes int) error
+}
+
+// setBuffers sets the buffer sizes of conn, conn returned by DialContext
+// is skipped if it doesn't support them.
+func (client *SocketClient) setBuffers(conn net.Conn) (err error) {
+	c, ok := conn.(bufferedConn)
+	if !ok {
+		return nil
+	}
+	if client.ReadBuffer > 0 {
+		if err = c.SetReadBuffer(client.ReadBuffer); err != nil {
+			return
+		}
+	}
+	if client.WriteBuffer > 0 {
+		err = c.SetWriteBuffer(client.WriteBuffer)
+	}
+	return
+}
+
+// connected returns the conn which is ready to use, or closes it and
+// returns DialError if err is not nil.
+func (client *SocketClient) connected(
+	uri string, conn net.Conn, err error) (net.Conn, error) {
 	if err != nil {
-		panic(err)
+		if conn != nil {
+			conn.Close()
+		}
+		return nil, &DialError{uri, err}
+	}
+	if client.TLSConfig != nil {
+		return tls.Client(conn, client.TLSConfig), nil
 	}
+	return conn, nil
 }
 
 // Close the client
 func (client *SocketClient) Close() {
-	close(client.connPool)
+	client.BaseClient.Close()
+	client.poolLocker.Lock()
+	client.closed = true
+	pools := client.pools
+	client.pools = make(map[string]*connPool)
+	client.poolLocker.Unlock()
+	for _, pool := range pools {
+		pool.closeAll()
+	}
 }
 
-func (client *SocketClient) close(conn net.Conn) {
-	conn.Close()
-	atomic.AddInt32(&client.connCount, -1)
+func fetchError(ctx context.Context, err error) error {
+	if _, ok := err.(*DialError); ok || err == errClientIsAlreadyClosed {
+		return err
+	}
+	return timeoutError(ctx)
 }
 
 func (client *SocketClient) fullDuplexSendAndReceive(
 	data []byte, context *ClientContext) (resp []byte, err error) {
+	ctx, cancel := withTimeout(context.Context(), context.Timeout)
+	defer cancel()
 	var entry *connEntry
-	for {
-		entry = client.fetchConn(true)
+	var conn net.Conn
+	id := atomic.AddUint32(&client.nextid, 1)
+	response := make(chan socketResponse, 1)
+	for conn == nil {
+		if entry, err = client.fetchConn(ctx, context.uri, true); err != nil {
+			return nil, fetchError(context.Context(), err)
+		}
 		entry.cond.L.Lock()
-		for entry.reqCount > 10 {
-			entry.cond.Wait()
+		err = waitContext(ctx, entry.cond, func() bool {
+			return entry.reqCount <= 10
+		})
+		if err == nil {
+			if conn = entry.conn; conn != nil {
+				entry.responses[id] = response
+				entry.reqCount++
+			}
 		}
 		entry.cond.L.Unlock()
-		if entry.conn != nil {
-			break
+		if err != nil {
+			entry.pool.put(entry, 0)
+			return nil, timeoutError(context.Context())
 		}
-		entry.reqCount = 0
-		entry.cond.Signal()
 	}
-	conn := entry.conn
-	id := atomic.AddUint32(&client.nextid, 1)
-	deadline := time.Now().Add(context.Timeout)
+	deadline, _ := ctx.Deadline()
 	err = conn.SetDeadline(deadline)
-	response := make(chan socketResponse)
 	if err == nil {
-		entry.cond.L.Lock()
-		entry.responses[id] = response
-		entry.reqCount++
-		entry.cond.L.Unlock()
 		dataPacket := packet{fullDuplex: true, body: data}
 		fromUint32(dataPacket.id[:], id)
 		err = sendData(conn, dataPacket)
@@ -226,55 +539,55 @@ func (client *SocketClient) fullDuplexSendAndReceive(
 		err = conn.SetDeadline(time.Time{})
 	}
 	if err != nil {
-		client.close(conn)
-		client.cond.Signal()
+		conn.Close()
 		return
 	}
-	client.connPool <- entry
-	client.cond.Signal()
+	entry.pool.put(entry, 0)
 	select {
 	case resp := <-response:
 		return resp.data, resp.err
-	case <-time.After(deadline.Sub(time.Now())):
+	case <-ctx.Done():
 		entry.cond.L.Lock()
-		delete(entry.responses, id)
-		entry.reqCount--
+		if _, ok := entry.responses[id]; ok {
+			delete(entry.responses, id)
+			entry.reqCount--
+		}
 		entry.cond.L.Unlock()
 		entry.cond.Signal()
-		return nil, ErrTimeout
+		return nil, timeoutError(context.Context())
 	}
 }
 
 func (client *SocketClient) halfDuplexSendAndReceive(
 	data []byte, context *ClientContext) ([]byte, error) {
-	entry := client.fetchConn(false)
-	conn := entry.conn
-	err := conn.SetDeadline(time.Now().Add(context.Timeout))
-	dataPacket := packet{body: data}
-	if err == nil {
-		err = sendData(conn, dataPacket)
+	ctx, cancel := withTimeout(context.Context(), context.Timeout)
+	defer cancel()
+	entry, err := client.fetchConn(ctx, context.uri, false)
+	if err != nil {
+		return nil, fetchError(context.Context(), err)
 	}
+	conn := entry.conn
+	deadline, _ := ctx.Deadline()
+	err = conn.SetDeadline(deadline)
+	var dataPacket packet
 	if err == nil {
-		err = recvData(conn, &dataPacket)
+		stop := abortConn(context.Context(), conn)
+		err = sendData(conn, packet{body: data})
+		if err == nil {
+			err = recvData(conn, &dataPacket)
+		}
+		stop()
 	}
 	if err == nil {
 		err = conn.SetDeadline(time.Time{})
 	}
 	if err != nil {
-		client.close(conn)
-		client.cond.Signal()
+		entry.pool.close(conn)
+		if e, ok := err.(net.Error); ok && e.Timeout() || ctx.Err() != nil {
+			err = timeoutError(context.Context())
+		}
 		return nil, err
 	}
-	if entry.timer == nil {
-		entry.timer = time.AfterFunc(client.IdleTimeout, func() {
-			client.close(conn)
-			entry.conn = nil
-			entry.timer = nil
-		})
-	} else {
-		entry.timer.Reset(client.IdleTimeout)
-	}
-	client.connPool <- entry
-	client.cond.Signal()
+	entry.pool.put(entry, client.IdleTimeout)
 	return dataPacket.body, nil
 }
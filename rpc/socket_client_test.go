@@ -0,0 +1,97 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/socket_client_test.go                              *
+ *                                                        *
+ * hprose socket client test for Go.                      *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestEntry(pool *connPool, fullDuplex bool) (*connEntry, net.Conn) {
+	conn, peer := net.Pipe()
+	entry := &connEntry{conn: conn, pool: pool}
+	if fullDuplex {
+		entry.cond = sync.NewCond(&sync.Mutex{})
+		entry.responses = make(map[uint32]chan socketResponse)
+	}
+	return entry, peer
+}
+
+func isClosedPipe(peer net.Conn) bool {
+	peer.SetReadDeadline(time.Now().Add(time.Second))
+	_, err := peer.Read(make([]byte, 1))
+	return err == io.EOF
+}
+
+func TestConnPoolPutBrokenFullDuplexEntry(t *testing.T) {
+	pool := newConnPool(1)
+	pool.count = 1
+	entry, _ := newTestEntry(pool, true)
+	entry.conn = nil
+	pool.put(entry, 0)
+	if len(pool.entries) != 0 || pool.count != 1 {
+		t.Fatalf("entries: %d, count: %d", len(pool.entries), pool.count)
+	}
+	pool.closeAll()
+	pool.put(entry, 0)
+	if pool.count != 1 {
+		t.Fatalf("count: %d", pool.count)
+	}
+}
+
+func TestConnPoolPutToClosedPool(t *testing.T) {
+	pool := newConnPool(2)
+	pool.count = 2
+	pool.closeAll()
+	half, halfPeer := newTestEntry(pool, false)
+	pool.put(half, 0)
+	if pool.count != 1 || !isClosedPipe(halfPeer) {
+		t.Fatalf("half duplex entry count: %d", pool.count)
+	}
+	// the full duplex entry is uncounted by fullDuplexReceive after its conn
+	// is closed.
+	full, fullPeer := newTestEntry(pool, true)
+	pool.put(full, 0)
+	if pool.count != 1 || !isClosedPipe(fullPeer) {
+		t.Fatalf("full duplex entry count: %d", pool.count)
+	}
+}
+
+func TestConnPoolResize(t *testing.T) {
+	pool := newConnPool(3)
+	half, halfPeer := newTestEntry(pool, false)
+	full, fullPeer := newTestEntry(pool, true)
+	broken, _ := newTestEntry(pool, true)
+	pool.count = 3
+	pool.put(half, 0)
+	pool.put(full, 0)
+	pool.put(broken, 0)
+	broken.conn = nil
+	pool.resize(0)
+	if len(pool.entries) != 0 || pool.count != 2 {
+		t.Fatalf("entries: %d, count: %d", len(pool.entries), pool.count)
+	}
+	if !isClosedPipe(halfPeer) || !isClosedPipe(fullPeer) {
+		t.Fatal("conns aren't closed")
+	}
+}
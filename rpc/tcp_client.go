@@ -12,7 +12,7 @@
  *                                                        *
  * hprose tcp client for Go.                              *
  *                                                        *
- * LastModified: Oct 5, 2016                              *
+ * LastModified: Oct 18, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -20,7 +20,7 @@
 package rpc
 
 import (
-	"crypto/tls"
+	"context"
 	"net"
 	"net/url"
 	"time"
@@ -43,10 +43,15 @@ func NewTCPClient(uri ...string) (client *TCPClient) {
 	client.NoDelay = true
 	client.KeepAlive = true
 	client.createConn = client.createTCPConn
+	client.setURIList = client.SetURIList
 	client.SetURIList(uri)
 	return
 }
 
+func newTCPClient(uri ...string) Client {
+	return NewTCPClient(uri...)
+}
+
 func checkTCPAddresses(client Client, uriList []string) {
 	for _, uri := range uriList {
 		if u, err := url.Parse(uri); err == nil {
@@ -63,27 +68,39 @@ func (client *TCPClient) SetURIList(uriList []string) {
 	client.BaseClient.SetURIList(uriList)
 }
 
-func (client *TCPClient) createTCPConn() net.Conn {
-	u, err := url.Parse(client.uri)
-	ifErrorPanic(err)
-	tcpaddr, err := net.ResolveTCPAddr(u.Scheme, u.Host)
-	ifErrorPanic(err)
-	conn, err := net.DialTCP(u.Scheme, nil, tcpaddr)
-	ifErrorPanic(err)
-	ifErrorPanic(conn.SetLinger(client.Linger))
-	ifErrorPanic(conn.SetNoDelay(client.NoDelay))
-	ifErrorPanic(conn.SetKeepAlive(client.KeepAlive))
-	if client.KeepAlivePeriod > 0 {
-		ifErrorPanic(conn.SetKeepAlivePeriod(client.KeepAlivePeriod))
+func (client *TCPClient) createTCPConn(
+	ctx context.Context, uri string) (conn net.Conn, err error) {
+	u, err := url.Parse(uri)
+	if err == nil {
+		conn, err = client.dial(ctx, u.Scheme, u.Host)
 	}
-	if client.ReadBuffer > 0 {
-		ifErrorPanic(conn.SetReadBuffer(client.ReadBuffer))
+	if err == nil {
+		err = client.setTCPOptions(conn)
 	}
-	if client.WriteBuffer > 0 {
-		ifErrorPanic(conn.SetWriteBuffer(client.WriteBuffer))
+	if err == nil {
+		err = client.setBuffers(conn)
+	}
+	return client.connected(uri, conn, err)
+}
+
+// setTCPOptions sets the tcp options of conn, conn returned by DialContext
+// is skipped if it isn't a *net.TCPConn.
+func (client *TCPClient) setTCPOptions(conn net.Conn) (err error) {
+	c, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
 	}
-	if client.TLSConfig != nil {
-		return tls.Client(conn, client.TLSConfig)
+	if err = c.SetLinger(client.Linger); err != nil {
+		return
 	}
-	return conn
+	if err = c.SetNoDelay(client.NoDelay); err != nil {
+		return
+	}
+	if err = c.SetKeepAlive(client.KeepAlive); err != nil {
+		return
+	}
+	if client.KeepAlivePeriod > 0 {
+		err = c.SetKeepAlivePeriod(client.KeepAlivePeriod)
+	}
+	return
 }
@@ -12,7 +12,7 @@
  *                                                        *
  * reflect types for Go.                                  *
  *                                                        *
- * LastModified: Sep 16, 2016                             *
+ * LastModified: Oct 17, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -20,6 +20,7 @@
 package rpc
 
 import (
+	"context"
 	"net"
 	"net/http"
 	"reflect"
@@ -30,7 +31,9 @@ import (
 
 var errorType = reflect.TypeOf((*error)(nil)).Elem()
 var interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+var stringType = reflect.TypeOf("")
 var contextType = reflect.TypeOf((*Context)(nil)).Elem()
+var goContextType = reflect.TypeOf((*context.Context)(nil)).Elem()
 var serviceContextType = reflect.TypeOf((*ServiceContext)(nil)).Elem()
 var httpContextType = reflect.TypeOf((*HTTPContext)(nil))
 var httpRequestType = reflect.TypeOf((*http.Request)(nil))
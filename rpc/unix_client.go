@@ -12,7 +12,7 @@
  *                                                        *
  * hprose unx client for Go.                              *
  *                                                        *
- * LastModified: Oct 5, 2016                              *
+ * LastModified: Oct 18, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -20,7 +20,7 @@
 package rpc
 
 import (
-	"crypto/tls"
+	"context"
 	"net"
 	"net/url"
 )
@@ -35,10 +35,15 @@ func NewUnixClient(uri ...string) (client *UnixClient) {
 	client = new(UnixClient)
 	client.initSocketClient()
 	client.createConn = client.createUnixConn
+	client.setURIList = client.SetURIList
 	client.SetURIList(uri)
 	return
 }
 
+func newUnixClient(uri ...string) Client {
+	return NewUnixClient(uri...)
+}
+
 func checkUnixAddresses(client Client, uriList []string) {
 	for _, uri := range uriList {
 		if u, err := url.Parse(uri); err == nil {
@@ -55,21 +60,14 @@ func (client *UnixClient) SetURIList(uriList []string) {
 	client.BaseClient.SetURIList(uriList)
 }
 
-func (client *UnixClient) createUnixConn() net.Conn {
-	u, err := url.Parse(client.uri)
-	ifErrorPanic(err)
-	unixaddr, err := net.ResolveUnixAddr(u.Scheme, u.Path)
-	ifErrorPanic(err)
-	conn, err := net.DialUnix(u.Scheme, nil, unixaddr)
-	ifErrorPanic(err)
-	if client.ReadBuffer > 0 {
-		ifErrorPanic(conn.SetReadBuffer(client.ReadBuffer))
-	}
-	if client.WriteBuffer > 0 {
-		ifErrorPanic(conn.SetWriteBuffer(client.WriteBuffer))
+func (client *UnixClient) createUnixConn(
+	ctx context.Context, uri string) (conn net.Conn, err error) {
+	u, err := url.Parse(uri)
+	if err == nil {
+		conn, err = client.dial(ctx, u.Scheme, u.Path)
 	}
-	if client.TLSConfig != nil {
-		return tls.Client(conn, client.TLSConfig)
+	if err == nil {
+		err = client.setBuffers(conn)
 	}
-	return conn
+	return client.connected(uri, conn, err)
 }
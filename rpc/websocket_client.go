@@ -12,7 +12,7 @@
  *                                                        *
  * hprose websocket client for Go.                        *
  *                                                        *
- * LastModified: Oct 8, 2016                              *
+ * LastModified: Oct 18, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -20,31 +20,71 @@
 package rpc
 
 import (
+	"context"
 	"crypto/tls"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
 )
 
-type reqeust struct {
-	id   uint32
-	data []byte
+// DefaultReconnectDelay is the default ReconnectDelay of WebSocketClient
+const DefaultReconnectDelay = 100 * time.Millisecond
+
+// DefaultMaxReconnectDelay is the default MaxReconnectDelay of
+// WebSocketClient
+const DefaultMaxReconnectDelay = 30 * time.Second
+
+// DefaultMaxQueuedCalls is the default MaxQueuedCalls of WebSocketClient
+const DefaultMaxQueuedCalls = 100
+
+type websocketCall struct {
+	id         uint32
+	data       []byte
+	response   chan socketResponse
+	idempotent bool
+}
+
+// websocketConn is the connection of a service address, it is reconnected
+// in background after it is broken. The calls are queued when it is
+// connecting, and they are sent after it is connected. The queued calls fail
+// when they are timeout or the client is closed, or at once if the first
+// connection fails.
+type websocketConn struct {
+	uri         string
+	conn        *websocket.Conn
+	writeLocker sync.Mutex
+	calls       map[uint32]*websocketCall
+	queue       []*websocketCall
+	connecting  bool
+	connected   bool
+	draining    bool
+	ctx         context.Context
+	cancel      context.CancelFunc
 }
 
 // WebSocketClient is hprose websocket client
 type WebSocketClient struct {
 	BaseClient
 	limiter
+	websocketKeepAlive
 	http.Header
-	dialer    websocket.Dialer
-	conn      *websocket.Conn
-	nextid    uint32
-	requests  chan reqeust
-	responses map[uint32]chan socketResponse
-	closed    bool
+	// ReconnectDelay is the delay of the first reconnection after the
+	// connection is broken, it is doubled after every failed reconnection
+	// up to MaxReconnectDelay, 0 disables the reconnection.
+	ReconnectDelay    time.Duration
+	MaxReconnectDelay time.Duration
+	// MaxQueuedCalls is the maximum number of the calls which are waiting
+	// for the connection of a service address, 0 means no limit.
+	MaxQueuedCalls int
+	dialer         websocket.Dialer
+	conns          map[string]*websocketConn
+	nextid         uint32
+	closed         bool
 }
 
 // NewWebSocketClient is the constructor of WebSocketClient
@@ -52,7 +92,14 @@ func NewWebSocketClient(uri ...string) (client *WebSocketClient) {
 	client = new(WebSocketClient)
 	client.initBaseClient()
 	client.initLimiter()
+	client.initKeepAlive()
+	client.ReconnectDelay = DefaultReconnectDelay
+	client.MaxReconnectDelay = DefaultMaxReconnectDelay
+	client.MaxQueuedCalls = DefaultMaxQueuedCalls
+	client.conns = make(map[string]*websocketConn)
 	client.closed = false
+	client.setURIList = client.SetURIList
+	client.closeURIs = client.closeConns
 	client.SetURIList(uri)
 	client.SendAndReceive = client.sendAndReceive
 	return
@@ -81,26 +128,103 @@ func (client *WebSocketClient) SetURIList(uriList []string) {
 	client.BaseClient.SetURIList(uriList)
 }
 
-func (client *WebSocketClient) close(err error) {
+// fail the call with err, client.cond.L must be locked.
+func (client *WebSocketClient) fail(call *websocketCall, err error) {
+	call.response <- socketResponse{nil, err}
+	client.unlimit()
+}
+
+// stop the conn and fail all of its calls with err, it is never
+// reconnected, client.cond.L must be locked.
+func (client *WebSocketClient) stop(c *websocketConn, err error) {
+	c.cancel()
+	c.draining = true
+	for _, call := range c.queue {
+		client.fail(call, err)
+	}
+	c.queue = nil
+	for id, call := range c.calls {
+		delete(c.calls, id)
+		client.fail(call, err)
+	}
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
+
+// disconnect is called when the conn is broken. If resend is true, the
+// in-flight idempotent calls are queued to be sent again after reconnection,
+// the others fail. When the heartbeat fails, resend is false, all of the
+// in-flight calls fail at once, because the peer is considered dead.
+func (client *WebSocketClient) disconnect(
+	c *websocketConn, conn *websocket.Conn, err error, resend bool) {
 	client.cond.L.Lock()
-	if err != nil && client.responses != nil {
-		for _, response := range client.responses {
-			response <- socketResponse{nil, err}
+	if c.conn != conn {
+		client.cond.L.Unlock()
+		return
+	}
+	c.conn = nil
+	conn.Close()
+	reconnect := client.ReconnectDelay > 0 && !client.closed && !c.draining
+	for id, call := range c.calls {
+		delete(c.calls, id)
+		if reconnect && resend && call.idempotent {
+			c.queue = append(c.queue, call)
+		} else {
+			client.fail(call, err)
 		}
 	}
-	client.responses = nil
-	if client.conn != nil {
-		client.conn.Close()
-		client.conn = nil
+	if reconnect {
+		client.connect(c)
+	} else {
+		if client.conns[c.uri] == c {
+			delete(client.conns, c.uri)
+		}
+		c.cancel()
 	}
-	client.reset()
 	client.cond.L.Unlock()
+	if event, ok := client.event.(onDisconnectEvent); ok {
+		event.OnDisconnect(client, c.uri, err)
+	}
+}
+
+// closeConns drains the conns of the removed service addresses, they are
+// closed after all of their pending responses are received.
+func (client *WebSocketClient) closeConns(uriList []string) {
+	client.cond.L.Lock()
+	for _, uri := range uriList {
+		if c := client.conns[uri]; c != nil {
+			delete(client.conns, uri)
+			if c.conn == nil {
+				client.stop(c, errServiceAddressIsRemoved)
+			} else {
+				c.draining = true
+				client.drained(c)
+			}
+		}
+	}
+	client.cond.L.Unlock()
+}
+
+// drained closes the draining conn if it has no pending response,
+// client.cond.L must be locked.
+func (client *WebSocketClient) drained(c *websocketConn) {
+	if c.draining && len(c.calls) == 0 && c.conn != nil {
+		c.cancel()
+		c.conn.Close()
+	}
 }
 
 // Close the client
 func (client *WebSocketClient) Close() {
+	client.BaseClient.Close()
+	client.cond.L.Lock()
 	client.closed = true
-	client.close(errClientIsAlreadyClosed)
+	for uri, c := range client.conns {
+		delete(client.conns, uri)
+		client.stop(c, errClientIsAlreadyClosed)
+	}
+	client.cond.L.Unlock()
 }
 
 // TLSClientConfig returns the tls.Config in hprose client
@@ -113,84 +237,215 @@ func (client *WebSocketClient) SetTLSClientConfig(config *tls.Config) {
 	client.dialer.TLSClientConfig = config
 }
 
-func (client *WebSocketClient) sendLoop() {
-	conn := client.conn
-	for request := range client.requests {
-		err := conn.WriteMessage(websocket.BinaryMessage, request.data)
-		if err != nil {
-			client.close(err)
-			break
+// getConn returns the conn of uri, it is created without connecting,
+// client.cond.L must be locked.
+func (client *WebSocketClient) getConn(uri string) *websocketConn {
+	c := client.conns[uri]
+	if c == nil {
+		c = &websocketConn{
+			uri:   uri,
+			calls: make(map[uint32]*websocketCall, client.MaxConcurrentRequests),
 		}
+		c.ctx, c.cancel = context.WithCancel(context.Background())
+		client.conns[uri] = c
 	}
-	client.requests = nil
+	return c
 }
 
-func (client *WebSocketClient) recvLoop() {
-	conn := client.conn
+// connect starts connecting in background, client.cond.L must be locked.
+func (client *WebSocketClient) connect(c *websocketConn) {
+	if !c.connecting {
+		c.connecting = true
+		go client.connectLoop(c)
+	}
+}
+
+// connectLoop dials until the conn is connected. If the first dialing fails,
+// the queued calls fail with the error at once, so they can switch to other
+// service addresses. After the conn has been connected, it is reconnected
+// with exponential backoff until it is stopped, and the calls are queued
+// until it is reconnected.
+func (client *WebSocketClient) connectLoop(c *websocketConn) {
+	delay := client.ReconnectDelay
+	for {
+		ctx, cancel := withTimeout(c.ctx, client.timeout)
+		conn, _, err := client.dialer.DialContext(ctx, c.uri, client.Header)
+		cancel()
+		client.cond.L.Lock()
+		if c.ctx.Err() != nil {
+			c.connecting = false
+			client.cond.L.Unlock()
+			if conn != nil {
+				conn.Close()
+			}
+			return
+		}
+		if err == nil {
+			client.connected(c, conn)
+			return
+		}
+		if !c.connected || delay <= 0 {
+			for _, call := range c.queue {
+				client.fail(call, err)
+			}
+			c.queue = nil
+			c.connecting = false
+			client.cond.L.Unlock()
+			return
+		}
+		client.cond.L.Unlock()
+		timer := time.NewTimer(jitter(delay))
+		select {
+		case <-timer.C:
+		case <-c.ctx.Done():
+			timer.Stop()
+		}
+		if delay *= 2; delay > client.MaxReconnectDelay {
+			delay = client.MaxReconnectDelay
+		}
+	}
+}
+
+// jitter returns a random delay between delay/2 and delay, so the clients
+// don't reconnect at the same time.
+func jitter(delay time.Duration) time.Duration {
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// connected sends the queued calls on the new conn, client.cond.L must be
+// locked, and it is unlocked by connected.
+func (client *WebSocketClient) connected(
+	c *websocketConn, conn *websocket.Conn) {
+	c.conn = conn
+	c.connecting = false
+	c.connected = true
+	queue := c.queue
+	c.queue = nil
+	for _, call := range queue {
+		c.calls[call.id] = call
+	}
+	client.cond.L.Unlock()
+	go client.recvLoop(c, conn)
+	if event, ok := client.event.(onConnectEvent); ok {
+		event.OnConnect(client, c.uri)
+	}
+	for _, call := range queue {
+		if client.write(c, conn, call) != nil {
+			return
+		}
+	}
+}
+
+func (client *WebSocketClient) write(
+	c *websocketConn, conn *websocket.Conn, call *websocketCall) error {
+	c.writeLocker.Lock()
+	conn.SetWriteDeadline(client.writeDeadline())
+	err := conn.WriteMessage(websocket.BinaryMessage, call.data)
+	c.writeLocker.Unlock()
+	if err != nil {
+		client.disconnect(c, conn, err, true)
+	}
+	return err
+}
+
+// recvLoop receives the responses until conn is broken, the pending calls
+// fail at once when the heartbeat fails, so they don't wait until timeout.
+func (client *WebSocketClient) recvLoop(
+	c *websocketConn, conn *websocket.Conn) {
+	stop := client.keepAlive(conn, func(err error) {
+		client.disconnect(c, conn, err, false)
+	})
+	defer stop()
 	for {
 		msgType, data, err := conn.ReadMessage()
 		if err != nil {
-			client.close(err)
-			break
+			err = heartbeatError(err)
+			client.disconnect(c, conn, err, err != ErrHeartbeatTimeout)
+			return
 		}
-		if msgType == websocket.BinaryMessage {
+		client.extendReadDeadline(conn)
+		if msgType == websocket.BinaryMessage && len(data) >= 4 {
 			id := toUint32(data)
 			client.cond.L.Lock()
-			response := client.responses[id]
-			if response != nil {
-				response <- socketResponse{data[4:], nil}
-				delete(client.responses, id)
+			if call := c.calls[id]; call != nil {
+				delete(c.calls, id)
+				call.response <- socketResponse{data[4:], nil}
+				client.unlimit()
+				client.drained(c)
 			}
-			client.unlimit()
 			client.cond.L.Unlock()
 		}
 	}
-	close(client.requests)
 }
 
-func (client *WebSocketClient) getConn(uri string) (err error) {
-	if client.conn == nil {
-		client.conn, _, err = client.dialer.Dial(uri, client.Header)
-		if err != nil {
-			return err
+// cancelCall removes the call which is timeout, client.cond.L must be
+// locked.
+func (client *WebSocketClient) cancelCall(
+	c *websocketConn, call *websocketCall) bool {
+	if c.calls[call.id] == call {
+		delete(c.calls, call.id)
+		return true
+	}
+	for i, queued := range c.queue {
+		if queued == call {
+			c.queue = append(c.queue[:i], c.queue[i+1:]...)
+			return true
 		}
-		count := client.MaxConcurrentRequests
-		client.requests = make(chan reqeust, count)
-		client.responses = make(map[uint32]chan socketResponse, count)
-		go client.sendLoop()
-		go client.recvLoop()
 	}
-	return nil
+	return false
 }
 
 func (client *WebSocketClient) sendAndReceive(
 	data []byte, context *ClientContext) ([]byte, error) {
+	ctx, cancel := withTimeout(context.Context(), context.Timeout)
+	defer cancel()
 	id := atomic.AddUint32(&client.nextid, 1)
 	buf := make([]byte, len(data)+4)
 	fromUint32(buf, id)
 	copy(buf[4:], data)
-	response := make(chan socketResponse)
+	call := &websocketCall{
+		id:         id,
+		data:       buf,
+		response:   make(chan socketResponse, 1),
+		idempotent: context.Idempotent,
+	}
 	client.cond.L.Lock()
-	client.limit()
+	if err := client.limit(ctx); err != nil {
+		client.cond.L.Unlock()
+		return nil, timeoutError(context.Context())
+	}
 	if client.closed {
+		client.unlimit()
 		client.cond.L.Unlock()
 		return nil, errClientIsAlreadyClosed
 	}
-	if err := client.getConn(client.uri); err != nil {
+	c := client.getConn(context.uri)
+	conn := c.conn
+	if conn != nil {
+		c.calls[id] = call
+	} else if client.MaxQueuedCalls > 0 &&
+		len(c.queue) >= client.MaxQueuedCalls {
+		client.unlimit()
 		client.cond.L.Unlock()
-		return nil, err
+		return nil, ErrTooManyQueuedCalls
+	} else {
+		c.queue = append(c.queue, call)
+		client.connect(c)
 	}
-	client.responses[id] = response
 	client.cond.L.Unlock()
-	client.requests <- reqeust{id, buf}
+	if conn != nil {
+		client.write(c, conn, call)
+	}
 	select {
-	case resp := <-response:
+	case resp := <-call.response:
 		return resp.data, resp.err
-	case <-time.After(context.Timeout):
+	case <-ctx.Done():
 		client.cond.L.Lock()
-		delete(client.responses, id)
-		client.unlimit()
+		if client.cancelCall(c, call) {
+			client.unlimit()
+			client.drained(c)
+		}
 		client.cond.L.Unlock()
-		return nil, ErrTimeout
+		return nil, timeoutError(context.Context())
 	}
 }
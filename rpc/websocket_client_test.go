@@ -0,0 +1,151 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/websocket_client_test.go                           *
+ *                                                        *
+ * hprose websocket client test for Go.                   *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"net"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// startDeadWebSocketServer starts a server which never responds, not even to
+// the pings.
+func startDeadWebSocketServer(t *testing.T) (uri string, stop func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var upgrader websocket.Upgrader
+	done := make(chan struct{})
+	go http.Serve(listener, http.HandlerFunc(
+		func(response http.ResponseWriter, request *http.Request) {
+			if conn, err := upgrader.Upgrade(response, request, nil); err == nil {
+				<-done
+				conn.Close()
+			}
+		}))
+	return "ws://" + listener.Addr().String() + "/", func() {
+		close(done)
+		listener.Close()
+	}
+}
+
+func TestWebSocketHeartbeatFailsPendingCalls(t *testing.T) {
+	uri, stop := startDeadWebSocketServer(t)
+	defer stop()
+	client := NewWebSocketClient(uri)
+	defer client.Close()
+	client.SetRetry(0)
+	client.PingInterval = 50 * time.Millisecond
+	client.PongTimeout = 50 * time.Millisecond
+	for _, idempotent := range []bool{false, true} {
+		start := time.Now()
+		_, err := client.Invoke("hello", nil, &InvokeSettings{
+			Idempotent:  idempotent,
+			ResultTypes: []reflect.Type{stringType},
+		})
+		if err != ErrHeartbeatTimeout {
+			t.Fatalf("idempotent: %v, err is %v, want %v",
+				idempotent, err, ErrHeartbeatTimeout)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("idempotent: %v, the call fails after %v",
+				idempotent, elapsed)
+		}
+	}
+}
+
+func TestWebSocketQueueWhileReconnecting(t *testing.T) {
+	service := NewWebSocketService()
+	service.AddFunction("hello", func(s string) string {
+		return "hello " + s
+	}, Options{})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	go http.Serve(listener, service)
+	client := NewWebSocketClient("ws://" + addr + "/")
+	defer client.Close()
+	client.SetRetry(0)
+	client.ReconnectDelay = 20 * time.Millisecond
+	client.MaxReconnectDelay = 50 * time.Millisecond
+	client.MaxQueuedCalls = 2
+	hello := func(timeout time.Duration) (string, error) {
+		results, err := client.Invoke("hello",
+			[]reflect.Value{reflect.ValueOf("world")},
+			&InvokeSettings{
+				Timeout:     timeout,
+				ResultTypes: []reflect.Type{stringType},
+			})
+		if err != nil {
+			return "", err
+		}
+		return results[0].String(), nil
+	}
+	if _, err := hello(0); err != nil {
+		t.Fatal(err)
+	}
+	client.cond.L.Lock()
+	c := client.conns[client.URI()]
+	conn := c.conn
+	client.cond.L.Unlock()
+	listener.Close()
+	conn.Close()
+	// several reconnections fail
+	time.Sleep(200 * time.Millisecond)
+	if _, err := hello(50 * time.Millisecond); err != ErrTimeout {
+		t.Fatalf("err is %v, want %v", err, ErrTimeout)
+	}
+	results := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			result, err := hello(5 * time.Second)
+			if err == nil && result != "hello world" {
+				t.Errorf("result is %q", result)
+			}
+			results <- err
+		}()
+	}
+	for queued := 0; queued < 2; {
+		time.Sleep(5 * time.Millisecond)
+		client.cond.L.Lock()
+		queued = len(c.queue)
+		client.cond.L.Unlock()
+	}
+	if _, err := hello(0); err != ErrTooManyQueuedCalls {
+		t.Fatalf("err is %v, want %v", err, ErrTooManyQueuedCalls)
+	}
+	if listener, err = net.Listen("tcp", addr); err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go http.Serve(listener, service)
+	for i := 0; i < 2; i++ {
+		if err := <-results; err != nil {
+			t.Error("the queued call fails:", err)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+/**********************************************************\
+|                                                          |
+|                          hprose                          |
+|                                                          |
+| Official WebSite: http://www.hprose.com/                 |
+|                   http://www.hprose.org/                 |
+|                                                          |
+\**********************************************************/
+/**********************************************************\
+ *                                                        *
+ * rpc/websocket_common.go                                *
+ *                                                        *
+ * hprose websocket common for Go.                        *
+ *                                                        *
+ * LastModified: Oct 18, 2026                             *
+ * Author: Ma Bingyao <andot@hprose.com>                  *
+ *                                                        *
+\**********************************************************/
+
+package rpc
+
+import (
+	"net"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultPingInterval is the default PingInterval of websocket client and
+// service
+const DefaultPingInterval = 30 * time.Second
+
+// DefaultPongTimeout is the default PongTimeout of websocket client and
+// service
+const DefaultPongTimeout = 10 * time.Second
+
+// DefaultWriteTimeout is the default WriteTimeout of websocket client and
+// service
+const DefaultWriteTimeout = 10 * time.Second
+
+// websocketKeepAlive detects the dead peer of the websocket connection.
+// A ping is sent every PingInterval, and the connection is considered dead
+// when nothing is received from the peer in PingInterval + PongTimeout.
+type websocketKeepAlive struct {
+	// PingInterval is the interval of the pings, 0 disables the heartbeat.
+	PingInterval time.Duration
+	// PongTimeout is how long to wait for the pong after the ping is sent.
+	PongTimeout time.Duration
+	// WriteTimeout is the deadline of every message sent, 0 means no
+	// deadline.
+	WriteTimeout time.Duration
+}
+
+func (k *websocketKeepAlive) initKeepAlive() {
+	k.PingInterval = DefaultPingInterval
+	k.PongTimeout = DefaultPongTimeout
+	k.WriteTimeout = DefaultWriteTimeout
+}
+
+func (k *websocketKeepAlive) extendReadDeadline(conn *websocket.Conn) {
+	if k.PingInterval > 0 {
+		conn.SetReadDeadline(time.Now().Add(k.PingInterval + k.PongTimeout))
+	}
+}
+
+func (k *websocketKeepAlive) writeDeadline() (deadline time.Time) {
+	if k.WriteTimeout > 0 {
+		deadline = time.Now().Add(k.WriteTimeout)
+	}
+	return
+}
+
+// keepAlive starts the heartbeat of conn, fail is called when the ping
+// can't be sent. The returned stop must be called after conn is closed.
+func (k *websocketKeepAlive) keepAlive(
+	conn *websocket.Conn, fail func(err error)) (stop func()) {
+	conn.SetPongHandler(func(string) error {
+		k.extendReadDeadline(conn)
+		return nil
+	})
+	conn.SetPingHandler(func(data string) error {
+		k.extendReadDeadline(conn)
+		err := conn.WriteControl(
+			websocket.PongMessage, []byte(data), k.writeDeadline())
+		if e, ok := err.(net.Error); err == websocket.ErrCloseSent ||
+			ok && e.Timeout() {
+			return nil
+		}
+		return err
+	})
+	k.extendReadDeadline(conn)
+	if k.PingInterval <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(k.PingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				err := conn.WriteControl(
+					websocket.PingMessage, nil, k.writeDeadline())
+				if err != nil {
+					fail(err)
+					return
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// heartbeatError returns ErrHeartbeatTimeout if err is caused by the read
+// deadline.
+func heartbeatError(err error) error {
+	if e, ok := err.(net.Error); ok && e.Timeout() {
+		return ErrHeartbeatTimeout
+	}
+	return err
+}
@@ -12,7 +12,7 @@
  *                                                        *
  * hprose websocket service for Go.                       *
  *                                                        *
- * LastModified: Oct 6, 2016                              *
+ * LastModified: Oct 18, 2026                             *
  * Author: Ma Bingyao <andot@hprose.com>                  *
  *                                                        *
 \**********************************************************/
@@ -40,6 +40,7 @@ type WebSocketContext struct {
 type WebSocketService struct {
 	HTTPService
 	websocket.Upgrader
+	websocketKeepAlive
 	contextPool chan *WebSocketContext
 }
 
@@ -71,6 +72,7 @@ func websocketFixArguments(args []reflect.Value, context ServiceContext) {
 func NewWebSocketService() (service *WebSocketService) {
 	service = new(WebSocketService)
 	service.initBaseHTTPService()
+	service.initKeepAlive()
 	service.contextPool = make(chan *WebSocketContext, runtime.NumCPU()*32)
 	service.FixArguments = websocketFixArguments
 	service.CheckOrigin = func(request *http.Request) bool {
@@ -116,7 +118,7 @@ func (service *WebSocketService) releaseContext(context *WebSocketContext) {
 // ServeHTTP is the hprose http handler method
 func (service *WebSocketService) ServeHTTP(
 	response http.ResponseWriter, request *http.Request) {
-	if request.Method == "GET" && strings.ToLower(request.Header.Get("connection")) != "upgrade" || request.Method == "POST" {
+	if request.Method == "GET" && strings.ToLower(request.Header.Get("connection")) != "upgrade" || request.Method == "POST" || request.Method == "PRI" {
 		service.HTTPService.ServeHTTP(response, request)
 		return
 	}
@@ -133,15 +135,62 @@ func (service *WebSocketService) ServeHTTP(
 	defer conn.Close()
 
 	mutex := new(sync.Mutex)
+	pingError := make(chan error, 1)
+	stop := service.keepAlive(conn, func(err error) {
+		pingError <- err
+		conn.Close()
+	})
 	for {
-		msgType, data, err := conn.ReadMessage()
-		if err != nil {
+		msgType, data, e := conn.ReadMessage()
+		if e != nil {
+			err = heartbeatError(e)
 			break
 		}
+		service.extendReadDeadline(conn)
 		if msgType == websocket.BinaryMessage {
 			go service.handle(data, mutex, response, request, conn)
 		}
 	}
+	stop()
+	select {
+	case err = <-pingError:
+	default:
+	}
+	service.fireWebSocketCloseEvent(err, response, request, conn)
+}
+
+type webSocketCloseEvent interface {
+	OnWebSocketClose(context *WebSocketContext, err error)
+}
+
+type webSocketCloseEvent2 interface {
+	OnWebSocketClose(context *WebSocketContext, err error) error
+}
+
+// fireWebSocketCloseEvent reports the websocket connection is closed, err is
+// ErrHeartbeatTimeout when the client is dead.
+func (service *WebSocketService) fireWebSocketCloseEvent(
+	err error,
+	response http.ResponseWriter,
+	request *http.Request,
+	conn *websocket.Conn) {
+	context := service.acquireContext()
+	context.initHTTPContext(service, response, request)
+	context.WebSocket = conn
+	defer func() {
+		if e := recover(); e != nil {
+			fireErrorEvent(service.Event, NewPanicError(e), context)
+		}
+		service.releaseContext(context)
+	}()
+	switch event := service.Event.(type) {
+	case webSocketCloseEvent:
+		event.OnWebSocketClose(context, err)
+	case webSocketCloseEvent2:
+		if err := event.OnWebSocketClose(context, err); err != nil {
+			fireErrorEvent(service.Event, err, context)
+		}
+	}
 }
 
 func (service *WebSocketService) handle(
@@ -156,6 +205,7 @@ func (service *WebSocketService) handle(
 	id := data[0:4]
 	data = service.Handle(data[4:], context)
 	mutex.Lock()
+	context.WebSocket.SetWriteDeadline(service.writeDeadline())
 	writer, err := context.WebSocket.NextWriter(websocket.BinaryMessage)
 	if err == nil {
 		_, err = writer.Write(id)